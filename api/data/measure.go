@@ -34,3 +34,9 @@ var MeasureQueryKindVersion = common.KindVersion{
 	Kind:    "measure-query",
 }
 var TopicMeasureQuery = bus.BiTopic(MeasureQueryKindVersion.String())
+
+var MeasureStatsKindVersion = common.KindVersion{
+	Version: "v1",
+	Kind:    "measure-stats",
+}
+var TopicMeasureStats = bus.BiTopic(MeasureStatsKindVersion.String())
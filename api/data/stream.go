@@ -34,3 +34,9 @@ var StreamQueryKindVersion = common.KindVersion{
 	Kind:    "stream-query",
 }
 var TopicStreamQuery = bus.BiTopic(StreamQueryKindVersion.String())
+
+var StreamStatsKindVersion = common.KindVersion{
+	Version: "v1",
+	Kind:    "stream-stats",
+}
+var TopicStreamStats = bus.BiTopic(StreamStatsKindVersion.String())
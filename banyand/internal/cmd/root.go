@@ -42,5 +42,6 @@ BanyanDB, as an observability database, aims to ingest, analyze and store Metric
 `,
 	}
 	cmd.AddCommand(newStandaloneCmd())
+	cmd.AddCommand(newVerifyCmd())
 	return cmd
 }
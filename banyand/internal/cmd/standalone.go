@@ -21,9 +21,11 @@ import (
 	"context"
 	"os"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/apache/skywalking-banyandb/banyand/discovery"
+	"github.com/apache/skywalking-banyandb/banyand/kv"
 	"github.com/apache/skywalking-banyandb/banyand/liaison"
 	"github.com/apache/skywalking-banyandb/banyand/metadata"
 	"github.com/apache/skywalking-banyandb/banyand/query"
@@ -80,6 +82,8 @@ func newStandaloneCmd() *cobra.Command {
 		tcp,
 	)
 	logging := logger.Logging{}
+	var blockCacheSize, memTableSize int64
+	var maintenance kv.Maintenance
 	standaloneCmd := &cobra.Command{
 		Use:     "standalone",
 		Version: version.Build(),
@@ -88,6 +92,11 @@ func newStandaloneCmd() *cobra.Command {
 			if err = config.Load("logging", cmd.Flags()); err != nil {
 				return err
 			}
+			kv.SetDefaultMemoryBudget(kv.NewMemoryBudget(blockCacheSize, memTableSize))
+			if maintenance.DiscardRatio <= 0 || maintenance.DiscardRatio >= 1 {
+				return errors.Errorf("the discard ratio %v is out of (0, 1)", maintenance.DiscardRatio)
+			}
+			kv.SetDefaultMaintenance(maintenance)
 			return logger.Init(logging)
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
@@ -103,6 +112,16 @@ func newStandaloneCmd() *cobra.Command {
 
 	standaloneCmd.Flags().StringVarP(&logging.Env, "logging.env", "", "dev", "the logging")
 	standaloneCmd.Flags().StringVarP(&logging.Level, "logging.level", "", "debug", "the level of logging")
+	standaloneCmd.Flags().Int64VarP(&blockCacheSize, "kv-block-cache-size", "", 1<<30,
+		"the size in bytes of the block caches shared by the kv stores, every store takes an equal share among the open stores when it's opened "+
+			"and keeps it till closing, so it's a soft hint rather than a cap on the total. 0 leaves every store with its own default cache")
+	standaloneCmd.Flags().Int64VarP(&memTableSize, "kv-memtable-size", "", 1<<30,
+		"the size in bytes of the memory tables shared by the kv stores, every store takes an equal share among the open stores when it's opened "+
+			"and keeps it till closing, so it's a soft hint rather than a cap on the total. 0 leaves every store with its own default memory tables")
+	standaloneCmd.Flags().DurationVarP(&maintenance.Interval, "kv-gc-interval", "", kv.DefaultMaintenanceInterval,
+		"the interval of collecting the value logs of the open kv stores, 0 disables the periodic collection")
+	standaloneCmd.Flags().Float64VarP(&maintenance.DiscardRatio, "kv-gc-discard-ratio", "", kv.DefaultDiscardRatio,
+		"a value log file is rewritten once the ratio of its discardable data exceeds it")
 	standaloneCmd.Flags().AddFlagSet(g.RegisterFlags().FlagSet)
 	return standaloneCmd
 }
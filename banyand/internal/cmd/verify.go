@@ -0,0 +1,107 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/version"
+)
+
+var errCorruptData = errors.New("found corrupt data")
+
+func newVerifyCmd() *cobra.Command {
+	var root, quarantineDir string
+	var verbose bool
+	verifyCmd := &cobra.Command{
+		Use:     "verify",
+		Version: version.Build(),
+		Short:   "Verify the data files against the manifests while the server is stopped",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := verify(root, quarantineDir)
+			if err != nil {
+				return err
+			}
+			var corrupt, quarantined int
+			out := cmd.OutOrStdout()
+			for _, r := range results {
+				if r.Status == tsdb.VerifyStatusCorrupt {
+					corrupt++
+					if r.QuarantinedPath != "" {
+						quarantined++
+					}
+				}
+				if r.Status == tsdb.VerifyStatusOK && !verbose {
+					continue
+				}
+				_, _ = fmt.Fprintf(out, "%s\t%s", r.Status, r.Path)
+				if len(r.Problems) > 0 {
+					_, _ = fmt.Fprintf(out, "\t%s", strings.Join(r.Problems, "; "))
+				}
+				if r.QuarantinedPath != "" {
+					_, _ = fmt.Fprintf(out, "\tquarantined to %s", r.QuarantinedPath)
+				}
+				_, _ = fmt.Fprintln(out)
+			}
+			_, _ = fmt.Fprintf(out, "verified %d blocks and segments, %d corrupt, %d quarantined\n", len(results), corrupt, quarantined)
+			if corrupt > quarantined {
+				return errors.WithMessagef(errCorruptData, "%d of them aren't quarantined", corrupt-quarantined)
+			}
+			return nil
+		},
+	}
+	verifyCmd.Flags().StringVar(&root, "root-path", "/tmp", "the root path of database, the cold root path could be verified as well")
+	verifyCmd.Flags().StringVar(&quarantineDir, "quarantine-path", "", "move the corrupt blocks and segments into the path, so the server starts without them")
+	verifyCmd.Flags().BoolVar(&verbose, "verbose", false, "print the verified ones as well")
+	return verifyCmd
+}
+
+// verify checks the location of every resource, which is <root>/<catalog>/data/<group>/<name>
+func verify(root, quarantineDir string) ([]tsdb.VerifyResult, error) {
+	locations, err := filepath.Glob(filepath.Join(root, "*", "data", "*", "*"))
+	if err != nil {
+		return nil, err
+	}
+	var results []tsdb.VerifyResult
+	for _, location := range locations {
+		if info, errStat := os.Stat(location); errStat != nil || !info.IsDir() {
+			continue
+		}
+		q := quarantineDir
+		if q != "" {
+			rel, errRel := filepath.Rel(root, location)
+			if errRel != nil {
+				return nil, errRel
+			}
+			q = filepath.Join(quarantineDir, rel)
+		}
+		r, errVerify := tsdb.Verify(location, q)
+		if errVerify != nil {
+			return nil, errVerify
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
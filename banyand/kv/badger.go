@@ -19,23 +19,37 @@ package kv
 
 import (
 	"bytes"
+	"container/heap"
 	"log"
 	"math"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/badger/v3"
 	"github.com/dgraph-io/badger/v3/bydb"
+	"github.com/dgraph-io/badger/v3/skl"
 	"github.com/dgraph-io/badger/v3/y"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 
 	"github.com/apache/skywalking-banyandb/pkg/encoding"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 )
 
+const (
+	maxHandoverAttempts   = 100
+	handoverRetryInterval = 10 * time.Millisecond
+)
+
 var (
 	_              Store           = (*badgerDB)(nil)
 	_              IndexStore      = (*badgerDB)(nil)
 	_              y.Iterator      = (*mergedIter)(nil)
 	_              TimeSeriesStore = (*badgerTSS)(nil)
+	_              Batch           = (*badgerBatch)(nil)
+	bitDelete      byte            = 1 << 0
 	bitMergeEntry  byte            = 1 << 3
 	ErrKeyNotFound                 = badger.ErrKeyNotFound
 )
@@ -44,16 +58,258 @@ type badgerTSS struct {
 	shardID int
 	dbOpts  badger.Options
 	db      *badger.DB
+	budget  *MemoryBudget
+	// maintenance is nil if the default one is used
+	maintenance *Maintenance
+	maintainer  *maintainer
 	badger.TSet
 }
 
 func (b *badgerTSS) Close() error {
 	if b.db != nil && !b.db.IsClosed() {
+		if b.budget != nil {
+			defer b.budget.release()
+		}
+		b.maintainer.stop()
 		return b.db.Close()
 	}
 	return nil
 }
 
+func (b *badgerTSS) NewBatch() Batch {
+	return &badgerBatch{
+		putAsync: b.PutAsync,
+	}
+}
+
+func (b *badgerTSS) Put(key, val []byte, ts uint64) error {
+	if err := checkValue(b.dbOpts, key, val); err != nil {
+		return err
+	}
+	return b.TSet.Put(key, val, ts)
+}
+
+func (b *badgerTSS) PutAsync(key, val []byte, ts uint64, f func(error)) error {
+	if err := checkValue(b.dbOpts, key, val); err != nil {
+		return err
+	}
+	return b.TSet.PutAsync(key, val, ts, f)
+}
+
+// DeletePrefix drops the keys from the tables directly, since the encoding of the memory tables loses the delete markers.
+// The memory tables are flushed ahead, otherwise, the dropping flushes them without the encoding.
+func (b *badgerTSS) DeletePrefix(prefix []byte) error {
+	if b.dbOpts.ReadOnly {
+		return errors.Wrapf(ErrReadOnly, "failed to delete the prefix %x", prefix)
+	}
+	if err := flush(b.db, b.dbOpts); err != nil {
+		return err
+	}
+	return b.db.DropPrefixBlocking(prefix)
+}
+
+// Snapshot flushes the memory tables first, whose raw entries are encoded into chunks by the flushing.
+// Then the chunks are handed over to the target directly.
+func (b *badgerTSS) Snapshot(dir string) error {
+	if err := flush(b.db, b.dbOpts); err != nil {
+		return errors.WithMessagef(err, "failed to flush the memory tables before the snapshot %s", dir)
+	}
+	return snapshot(b.db, b.dbOpts, dir)
+}
+
+func (b *badgerTSS) RequestCompaction() {
+	b.maintainer.request()
+}
+
+func (b *badgerTSS) Stats() Stats {
+	return stats(b.db, b.dbOpts.Dir)
+}
+
+// Scan walks the raw entries in the memory tables and the encoded chunks in the tables, whose versions are their start time.
+// The returned iterator decodes the chunks lazily and holds the tables until it's closed.
+func (b *badgerTSS) Scan(key []byte, startTs, endTs uint64, reverse bool) (TimeSeriesIterator, error) {
+	result := &tsIterator{
+		tss:     b,
+		key:     y.Copy(key),
+		startTs: startTs,
+		endTs:   endTs,
+		points:  tsHeap{reverse: reverse},
+	}
+	if startTs > endTs {
+		return result, nil
+	}
+	if b.db.IsClosed() {
+		return nil, badger.ErrDBClosed
+	}
+	opts := badger.DefaultIteratorOptions
+	// The ascending order walks the chunks from the earliest one,
+	// the descending one walks them from the latest one starting before endTs.
+	opts.Reverse = !reverse
+	result.iter = newIterator(b.db, opts)
+	if reverse {
+		result.iter.Seek(y.KeyWithTs(key, endTs))
+	} else {
+		result.iter.Seek(y.KeyWithTs(key, 0))
+	}
+	return result, nil
+}
+
+// inMemTable checks whether the raw entry is in memory tables. Get returns it directly,
+// otherwise, Get returns a value decoded from the entry.
+func (b *badgerTSS) inMemTable(it y.Iterator) bool {
+	v := it.Value()
+	val, err := b.TSet.Get(y.ParseKey(it.Key()), y.ParseTs(it.Key()))
+	return err == nil && bytes.Equal(val, v.Value)
+}
+
+var _ TimeSeriesIterator = (*tsIterator)(nil)
+
+// tsIterator merges the points of the chunks which might overlap each other.
+type tsIterator struct {
+	tss     *badgerTSS
+	key     []byte
+	startTs uint64
+	endTs   uint64
+	iter    y.Iterator
+	points  tsHeap
+	cur     *tsPoint
+	err     error
+}
+
+func (i *tsIterator) Next() bool {
+	if i.err != nil {
+		return false
+	}
+	for {
+		if i.err = i.open(); i.err != nil {
+			return false
+		}
+		if i.points.Len() < 1 {
+			i.cur = nil
+			return false
+		}
+		p := heap.Pop(&i.points).(*tsPoint)
+		// The point of the latest chunk wins if several ones share a timestamp, which is the one TSet.Get returns.
+		if i.cur != nil && i.cur.ts == p.ts {
+			continue
+		}
+		i.cur = p
+		return true
+	}
+}
+
+// open decodes the chunks which might hold the next point.
+// The points of a chunk aren't earlier than its start, so the ascending order stops at the chunk starting after the earliest pending point.
+// A chunk's end is unknown until it's decoded, so the descending order decodes all the chunks starting before endTs.
+func (i *tsIterator) open() error {
+	if i.iter == nil {
+		return nil
+	}
+	for ; i.iter.Valid() && bytes.Equal(y.ParseKey(i.iter.Key()), i.key); i.iter.Next() {
+		version := y.ParseTs(i.iter.Key())
+		if !i.points.reverse {
+			if version > i.endTs {
+				break
+			}
+			if i.points.Len() > 0 && version > i.points.items[0].ts {
+				return nil
+			}
+		}
+		if err := i.decode(version, i.iter.Value()); err != nil {
+			return errors.WithMessagef(err, "failed to decode the chunk starting at %d", version)
+		}
+	}
+	err := i.iter.Close()
+	i.iter = nil
+	return err
+}
+
+func (i *tsIterator) decode(version uint64, v y.ValueStruct) error {
+	if i.tss.dbOpts.DecoderPool == nil || (v.Meta&bydb.BitCompact == 0 && i.tss.inMemTable(i.iter)) {
+		if version >= i.startTs && version <= i.endTs {
+			heap.Push(&i.points, &tsPoint{ts: version, version: version, val: y.Copy(v.Value)})
+		}
+		return nil
+	}
+	pool := i.tss.dbOpts.DecoderPool
+	decoder := pool.Get(i.key)
+	defer pool.Put(decoder)
+	if err := decoder.Decode(i.key, v.Value); err != nil {
+		return err
+	}
+	it := decoder.Iterator()
+	for it.Next() {
+		if ts := it.Time(); ts >= i.startTs && ts <= i.endTs {
+			heap.Push(&i.points, &tsPoint{ts: ts, version: version, val: y.Copy(it.Val())})
+		}
+	}
+	return it.Error()
+}
+
+func (i *tsIterator) Time() uint64 {
+	return i.cur.ts
+}
+
+func (i *tsIterator) Val() []byte {
+	return i.cur.val
+}
+
+func (i *tsIterator) Close() error {
+	i.points.items = nil
+	i.cur = nil
+	if i.iter == nil {
+		return nil
+	}
+	err := i.iter.Close()
+	i.iter = nil
+	return err
+}
+
+type tsPoint struct {
+	ts      uint64
+	version uint64
+	val     []byte
+}
+
+var _ heap.Interface = (*tsHeap)(nil)
+
+// tsHeap orders the points by their timestamps, then by the versions of their chunks in the descending order.
+type tsHeap struct {
+	items   []*tsPoint
+	reverse bool
+}
+
+func (h *tsHeap) Len() int {
+	return len(h.items)
+}
+
+func (h *tsHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if a.ts == b.ts {
+		return a.version > b.version
+	}
+	if h.reverse {
+		return a.ts > b.ts
+	}
+	return a.ts < b.ts
+}
+
+func (h *tsHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *tsHeap) Push(x interface{}) {
+	h.items = append(h.items, x.(*tsPoint))
+}
+
+func (h *tsHeap) Pop() interface{} {
+	n := len(h.items)
+	x := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	return x
+}
+
 type mergedIter struct {
 	delegated Iterator
 	valid     bool
@@ -108,6 +364,10 @@ type badgerDB struct {
 	shardID int
 	dbOpts  badger.Options
 	db      *badger.DB
+	budget  *MemoryBudget
+	// maintenance is nil if the default one is used
+	maintenance *Maintenance
+	maintainer  *maintainer
 }
 
 func (b *badgerDB) Handover(iterator Iterator) error {
@@ -121,7 +381,7 @@ func (b *badgerDB) Scan(key []byte, opt ScanOpts, f ScanFunc) error {
 	opts.PrefetchSize = opt.PrefetchSize
 	opts.PrefetchValues = opt.PrefetchValues
 	opts.Reverse = opt.Reverse
-	it := b.db.NewIterator(opts)
+	it := newLiveIterator(b.db, opts)
 	defer func() {
 		_ = it.Close()
 	}()
@@ -140,6 +400,112 @@ func (b *badgerDB) Scan(key []byte, opt ScanOpts, f ScanFunc) error {
 	return nil
 }
 
+// newIterator creates an internal iterator of the db. A read-only db without any table has no iterator,
+// an empty one is returned instead.
+func newIterator(db *badger.DB, opts badger.IteratorOptions) y.Iterator {
+	if it := db.NewIterator(opts); it != nil {
+		return it
+	}
+	return emptyIterator{}
+}
+
+type emptyIterator struct{}
+
+func (emptyIterator) Next() {}
+
+func (emptyIterator) Rewind() {}
+
+func (emptyIterator) Seek(_ []byte) {}
+
+func (emptyIterator) Key() []byte { return nil }
+
+func (emptyIterator) Value() y.ValueStruct { return y.ValueStruct{} }
+
+func (emptyIterator) Valid() bool { return false }
+
+func (emptyIterator) Close() error { return nil }
+
+var _ y.Iterator = (*liveIterator)(nil)
+
+// liveIterator skips the delete markers along with the versions shadowed by them.
+// A marker shadows the versions of its key which are lower than or equal to it.
+type liveIterator struct {
+	y.Iterator
+	db      *badger.DB
+	reverse bool
+	// lookahead finds the marker of a key, whose versions are walked in the ascending order by a reverse iterator
+	lookahead y.Iterator
+	key       []byte
+	deleted   bool
+	deletedAt uint64
+}
+
+func newLiveIterator(db *badger.DB, opts badger.IteratorOptions) *liveIterator {
+	return &liveIterator{
+		Iterator: newIterator(db, opts),
+		db:       db,
+		reverse:  opts.Reverse,
+	}
+}
+
+func (i *liveIterator) Next() {
+	i.Iterator.Next()
+	i.skipDeleted()
+}
+
+func (i *liveIterator) Rewind() {
+	i.Iterator.Rewind()
+	i.skipDeleted()
+}
+
+func (i *liveIterator) Seek(key []byte) {
+	i.Iterator.Seek(key)
+	i.skipDeleted()
+}
+
+func (i *liveIterator) skipDeleted() {
+	for ; i.Iterator.Valid(); i.Iterator.Next() {
+		key, version := y.ParseKey(i.Iterator.Key()), y.ParseTs(i.Iterator.Key())
+		if !bytes.Equal(key, i.key) {
+			i.key = y.SafeCopy(i.key, key)
+			i.deleted = false
+			if i.reverse {
+				i.deletedAt, i.deleted = i.lookUpMarker(key)
+			}
+		}
+		// the highest marker comes first in the descending order of the versions
+		if !i.deleted && i.Iterator.Value().Meta&bitDelete > 0 {
+			i.deletedAt, i.deleted = version, true
+		}
+		if !i.deleted || version > i.deletedAt {
+			return
+		}
+	}
+}
+
+func (i *liveIterator) lookUpMarker(key []byte) (uint64, bool) {
+	if i.lookahead == nil {
+		i.lookahead = newIterator(i.db, badger.DefaultIteratorOptions)
+	}
+	for i.lookahead.Seek(y.KeyWithTs(key, math.MaxUint64)); i.lookahead.Valid(); i.lookahead.Next() {
+		if !bytes.Equal(y.ParseKey(i.lookahead.Key()), key) {
+			break
+		}
+		if i.lookahead.Value().Meta&bitDelete > 0 {
+			return y.ParseTs(i.lookahead.Key()), true
+		}
+	}
+	return 0, false
+}
+
+func (i *liveIterator) Close() error {
+	err := i.Iterator.Close()
+	if i.lookahead != nil {
+		err = multierr.Append(err, i.lookahead.Close())
+	}
+	return err
+}
+
 var _ Iterator = (*iterator)(nil)
 
 type iterator struct {
@@ -185,28 +551,225 @@ func (b *badgerDB) NewIterator(opt ScanOpts) Iterator {
 	opts.PrefetchValues = opt.PrefetchValues
 	opts.Reverse = opt.Reverse
 	opts.Prefix = opt.Prefix
-	it := b.db.NewIterator(opts)
+	it := newLiveIterator(b.db, opts)
 	return &iterator{
 		delegated: it,
 		reverse:   opts.Reverse,
 	}
 }
 
+func (b *badgerDB) Snapshot(dir string) error {
+	return snapshot(b.db, b.dbOpts, dir)
+}
+
+// snapshot hands the entries of the source over to a new db in the dir as a table.
+// The snapshot of a read-only source is writable.
+func snapshot(source *badger.DB, opts badger.Options, dir string) error {
+	target, err := badger.OpenManaged(opts.WithDir(dir).WithValueDir(dir).WithReadOnly(false).WithInMemory(false))
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the snapshot %s", dir)
+	}
+	err = target.HandoverIterator(newIterator(source, badger.DefaultIteratorOptions))
+	return multierr.Combine(err, target.Close())
+}
+
+// flush hands an empty memory table over to the db and waits for its flushing,
+// which happens after the memory tables ahead of it are flushed.
+func flush(db *badger.DB, opts badger.Options) error {
+	if opts.ReadOnly {
+		return nil
+	}
+	flushed := make(chan struct{})
+	if err := handover(func() error {
+		return db.HandoverSkiplist(skl.NewSkiplist(2*int64(skl.MaxNodeSize)), func() {
+			close(flushed)
+		})
+	}); err != nil {
+		return err
+	}
+	<-flushed
+	return nil
+}
+
+// handover retries the fn handing memory tables over to the db, which fails if the flushing queue is full
+func handover(fn func() error) (err error) {
+	for i := 0; i < maxHandoverAttempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		time.Sleep(handoverRetryInterval)
+	}
+	return err
+}
+
+// checkValue rejects the values exceeding the value threshold.
+// All values are kept in the LSM tree, a larger one might overflow the memory table.
+func checkValue(opts badger.Options, key, val []byte) error {
+	if int64(len(val)) > opts.ValueThreshold {
+		return errors.Wrapf(ErrUnsupportedValue, "the value of key %s is larger than %d bytes", key, opts.ValueThreshold)
+	}
+	return nil
+}
+
+func (b *badgerDB) RequestCompaction() {
+	b.maintainer.request()
+}
+
+func (b *badgerDB) Stats() Stats {
+	return stats(b.db, b.dbOpts.Dir)
+}
+
+// inMemory drops the directories, which aren't allowed in the disk-less mode
+func inMemory(opts badger.Options) badger.Options {
+	return opts.WithInMemory(true).WithDir("").WithValueDir("")
+}
+
+func stats(db *badger.DB, dir string) (s Stats) {
+	_ = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			s.DiskSize += info.Size()
+		}
+		return nil
+	})
+	iter := newLiveIterator(db, badger.DefaultIteratorOptions)
+	defer func() {
+		_ = iter.Close()
+	}()
+	var lastKey []byte
+	for iter.Rewind(); iter.Valid(); iter.Next() {
+		s.EntryCount++
+		key := y.ParseKey(iter.Key())
+		if lastKey == nil || !bytes.Equal(lastKey, key) {
+			s.KeyCount++
+			lastKey = y.Copy(key)
+		}
+	}
+	return s
+}
+
 func (b *badgerDB) Close() error {
 	if b.db != nil && !b.db.IsClosed() {
+		if b.budget != nil {
+			defer b.budget.release()
+		}
+		b.maintainer.stop()
 		return b.db.Close()
 	}
 	return nil
 }
 
 func (b *badgerDB) Put(key, val []byte) error {
-	return b.db.Put(y.KeyWithTs(key, math.MaxInt64), val)
+	return b.PutWithVersion(key, val, math.MaxInt64)
 }
 
 func (b *badgerDB) PutWithVersion(key, val []byte, version uint64) error {
+	if err := checkValue(b.dbOpts, key, val); err != nil {
+		return err
+	}
 	return b.db.Put(y.KeyWithTs(key, version), val)
 }
 
+func (b *badgerDB) NewBatch() Batch {
+	return &badgerBatch{
+		putAsync: func(key, val []byte, version uint64, f func(error)) error {
+			if err := checkValue(b.dbOpts, key, val); err != nil {
+				return err
+			}
+			return b.db.PutAsync(y.KeyWithTs(key, version), val, f)
+		},
+	}
+}
+
+// Delete puts a marker on the highest version of the key, which shadows all versions of it.
+// A version put after the deletion should be higher than the deleted ones to be visible.
+func (b *badgerDB) Delete(key []byte) error {
+	return b.deleteKeys(key, func(k []byte) bool {
+		return bytes.Equal(k, key)
+	})
+}
+
+// DeletePrefix drops the keys without blocking the writes, the compaction reclaims their space
+func (b *badgerDB) DeletePrefix(prefix []byte) error {
+	if err := flush(b.db, b.dbOpts); err != nil {
+		return err
+	}
+	return handover(func() error {
+		return b.db.DropPrefixNonBlocking(prefix)
+	})
+}
+
+// DeleteRange drops the range as a prefix if it covers all keys starting with its start, otherwise,
+// it puts a marker on each key in the range.
+func (b *badgerDB) DeleteRange(start, end []byte) error {
+	if bytes.Compare(start, end) >= 0 {
+		return nil
+	}
+	if bytes.Equal(end, prefixEnd(start)) {
+		return b.DeletePrefix(start)
+	}
+	return b.deleteKeys(start, func(k []byte) bool {
+		return bytes.Compare(k, end) < 0
+	})
+}
+
+// deleteKeys puts a marker on the highest version of every key from the seek key on till the condition fails.
+// The markers are handed over to the db as a memory table, then the deletion waits for its flushing.
+// The pending writes are flushed ahead, a marker merged with the same version in one flushing loses to it.
+func (b *badgerDB) deleteKeys(seek []byte, cond func(key []byte) bool) error {
+	if err := flush(b.db, b.dbOpts); err != nil {
+		return err
+	}
+	var markers [][]byte
+	size := int64(skl.MaxNodeSize)
+	iter := newLiveIterator(b.db, badger.DefaultIteratorOptions)
+	var lastKey []byte
+	for iter.Seek(y.KeyWithTs(seek, math.MaxUint64)); iter.Valid(); iter.Next() {
+		key := y.ParseKey(iter.Key())
+		if !cond(key) {
+			break
+		}
+		if bytes.Equal(key, lastKey) {
+			continue
+		}
+		lastKey = y.SafeCopy(lastKey, key)
+		markers = append(markers, y.Copy(iter.Key()))
+		size += int64(skl.MaxNodeSize + len(iter.Key()) + 16)
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	if len(markers) < 1 {
+		return nil
+	}
+	builder := skl.NewBuilder(size)
+	for _, m := range markers {
+		builder.Add(m, y.ValueStruct{Meta: bitDelete})
+	}
+	sl := builder.Skiplist()
+	flushed := make(chan struct{})
+	if err := handover(func() error {
+		return b.db.HandoverSkiplist(sl, func() {
+			close(flushed)
+		})
+	}); err != nil {
+		return err
+	}
+	<-flushed
+	return nil
+}
+
+// prefixEnd returns the lowest key greater than all keys starting with the prefix, nil means there is no such key
+func prefixEnd(prefix []byte) []byte {
+	end := y.Copy(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
 func (b *badgerDB) Get(key []byte) ([]byte, error) {
 	v, err := b.db.Get(y.KeyWithTs(key, math.MaxInt64))
 	if err == badger.ErrKeyNotFound {
@@ -215,11 +778,17 @@ func (b *badgerDB) Get(key []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if v.Meta&bitDelete > 0 {
+		return nil, ErrKeyNotFound
+	}
 	return v.Value, nil
 }
 
 func (b *badgerDB) GetAll(key []byte, applyFn func([]byte) error) error {
-	iter := b.db.NewIterator(badger.DefaultIteratorOptions)
+	iter := newLiveIterator(b.db, badger.DefaultIteratorOptions)
+	defer func() {
+		_ = iter.Close()
+	}()
 	var count int
 	for iter.Seek(y.KeyWithTs(key, math.MaxInt64)); iter.Valid(); iter.Next() {
 		if !bytes.Equal(y.ParseKey(iter.Key()), key) {
@@ -237,6 +806,57 @@ func (b *badgerDB) GetAll(key []byte, applyFn func([]byte) error) error {
 	return ErrKeyNotFound
 }
 
+type batchEntry struct {
+	key     []byte
+	val     []byte
+	version uint64
+}
+
+// badgerBatch sends all entries to the write channel before waiting for them.
+// The badger writer merges the pending requests into one write to the value log and the memory table.
+type badgerBatch struct {
+	putAsync func(key, val []byte, version uint64, f func(error)) error
+	entries  []batchEntry
+}
+
+func (b *badgerBatch) Put(key, val []byte, version uint64) {
+	b.entries = append(b.entries, batchEntry{key: key, val: val, version: version})
+}
+
+func (b *badgerBatch) Len() int {
+	return len(b.entries)
+}
+
+func (b *badgerBatch) Commit() error {
+	entries := b.entries
+	b.entries = nil
+	if len(entries) < 1 {
+		return nil
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var err error
+	appendErr := func(e error) {
+		mu.Lock()
+		err = multierr.Append(err, e)
+		mu.Unlock()
+	}
+	for _, e := range entries {
+		wg.Add(1)
+		if errPut := b.putAsync(e.key, e.val, e.version, func(errWrite error) {
+			if errWrite != nil {
+				appendErr(errWrite)
+			}
+			wg.Done()
+		}); errPut != nil {
+			appendErr(errPut)
+			wg.Done()
+		}
+	}
+	wg.Wait()
+	return err
+}
+
 // badgerLog delegates the zap log to the badger logger
 type badgerLog struct {
 	*log.Logger
@@ -0,0 +1,371 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/pkg/encoding"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/test"
+)
+
+// backends run the behavior tests over the stores on the disk and the ones kept in the memory.
+// A store in the memory isn't reopened since its values are lost after closing.
+var backends = []backend{
+	{name: "badger"},
+	{name: "in-memory", inMemory: true},
+}
+
+type backend struct {
+	name     string
+	inMemory bool
+}
+
+func (b backend) storeOpts(options ...StoreOptions) []StoreOptions {
+	if b.inMemory {
+		return append(options, StoreWithInMemory())
+	}
+	return options
+}
+
+func (b backend) tssOpts(options ...TimeSeriesOptions) []TimeSeriesOptions {
+	if b.inMemory {
+		return append(options, TSSWithInMemory())
+	}
+	return options
+}
+
+func TestStore_Delete(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			req.NoError(logger.Init(logger.Logging{
+				Env:   "dev",
+				Level: "warn",
+			}))
+			path, deferFn := test.Space(req)
+			defer deferFn()
+			open := func() Store {
+				s, err := OpenStore(0, path, b.storeOpts(StoreWithLogger(logger.GetLogger("test")))...)
+				req.NoError(err)
+				return s
+			}
+			s := open()
+			for _, k := range []string{"a", "ab", "b1", "b2", "c", "d"} {
+				req.NoError(s.Put([]byte(k), []byte("val-"+k)))
+			}
+			req.NoError(s.PutWithVersion([]byte("v"), []byte("v1"), 1))
+			req.NoError(s.PutWithVersion([]byte("v"), []byte("v2"), 2))
+
+			req.NoError(s.Delete([]byte("a")))
+			req.NoError(s.Delete([]byte("v")))
+			req.NoError(s.DeletePrefix([]byte("b")))
+			req.NoError(s.DeleteRange([]byte("c"), []byte("d")))
+
+			keys := func(s Store, reverse bool) (result []string) {
+				opts := DefaultScanOpts
+				opts.Reverse = reverse
+				iter := s.NewIterator(opts)
+				defer func() {
+					tester.NoError(iter.Close())
+				}()
+				for iter.Rewind(); iter.Valid(); iter.Next() {
+					result = append(result, string(iter.Key()))
+				}
+				return result
+			}
+			verify := func(s Store) {
+				_, err := s.Get([]byte("a"))
+				tester.ErrorIs(err, ErrKeyNotFound)
+				val, err := s.Get([]byte("ab"))
+				tester.NoError(err)
+				tester.Equal([]byte("val-ab"), val)
+				tester.ErrorIs(s.GetAll([]byte("v"), func([]byte) error { return nil }), ErrKeyNotFound)
+				tester.Equal([]string{"ab", "d"}, keys(s, false))
+				tester.Equal([]string{"d", "ab"}, keys(s, true))
+				tester.Equal(uint64(2), s.Stats().KeyCount)
+			}
+			verify(s)
+			if !b.inMemory {
+				req.NoError(s.Close())
+				s = open()
+			}
+			defer s.Close()
+			verify(s)
+			req.NoError(s.Put([]byte("a"), []byte("val-a")))
+			val, err := s.Get([]byte("a"))
+			tester.NoError(err)
+			tester.Equal([]byte("val-a"), val, "a deleted key could be put again")
+		})
+	}
+}
+
+func TestStore_Maintenance(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	path, deferFn := test.Space(req)
+	defer deferFn()
+	m := Maintenance{Interval: 10 * time.Millisecond, DiscardRatio: DefaultDiscardRatio}
+	open := func(options ...StoreOptions) *badgerDB {
+		s, err := OpenStore(0, path, append(options, StoreWithLogger(logger.GetLogger("test")), StoreWithMaintenance(m))...)
+		req.NoError(err)
+		return s.(*badgerDB)
+	}
+	s := open()
+	// every round of the puts is flushed into a table, till the tables are enough to be compacted
+	for i := 0; i < 100; i++ {
+		req.NoError(s.Put([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("val-%d", i))))
+		if i%20 == 19 {
+			req.NoError(flush(s.db, s.dbOpts))
+		}
+	}
+	// storedKeys counts the keys in the tables, including the markers and the versions shadowed by them
+	storedKeys := func() (count uint32) {
+		for _, t := range s.db.Tables() {
+			count += t.KeyCount
+		}
+		return count
+	}
+	req.NoError(s.DeletePrefix([]byte("key-1")))
+	tester.Equal(uint32(111), storedKeys(), "the deleted keys are marked")
+	tester.Equal(uint64(89), s.Stats().KeyCount)
+	s.RequestCompaction()
+	tester.Eventually(func() bool {
+		return storedKeys() == 89
+	}, 10*time.Second, 10*time.Millisecond, "the compaction should discard the deleted keys")
+	val, err := s.Get([]byte("key-2"))
+	tester.NoError(err)
+	tester.Equal([]byte("val-2"), val)
+	_, err = s.Get([]byte("key-10"))
+	tester.ErrorIs(err, ErrKeyNotFound)
+	req.NoError(s.Close())
+
+	s = open(StoreWithReadOnly())
+	defer s.Close()
+	tester.Nil(s.maintainer, "a read-only store is left untouched")
+	s.RequestCompaction()
+	_, err = s.Get([]byte("key-10"))
+	tester.ErrorIs(err, ErrKeyNotFound)
+}
+
+func TestStore_Snapshot(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			req.NoError(logger.Init(logger.Logging{
+				Env:   "dev",
+				Level: "warn",
+			}))
+			path, deferFn := test.Space(req)
+			defer deferFn()
+			snapshotPath, deferSnapshot := test.Space(req)
+			defer deferSnapshot()
+			s, err := OpenStore(0, path, b.storeOpts(StoreWithLogger(logger.GetLogger("test")))...)
+			req.NoError(err)
+			defer s.Close()
+			large := bytes.Repeat([]byte("v"), int(s.(*badgerDB).dbOpts.ValueThreshold))
+			req.NoError(s.Put([]byte("large"), large))
+			tester.ErrorIs(s.Put([]byte("too-large"), append(large, 'v')), ErrUnsupportedValue)
+			req.NoError(s.Put([]byte("small"), []byte("v")))
+			req.NoError(s.Snapshot(snapshotPath))
+
+			snapshot, err := OpenStore(0, snapshotPath, StoreWithLogger(logger.GetLogger("test")))
+			req.NoError(err)
+			defer snapshot.Close()
+			val, err := snapshot.Get([]byte("large"))
+			req.NoError(err)
+			tester.Equal(large, val, "a large value is kept in the LSM tree, which could be copied")
+			val, err = snapshot.Get([]byte("small"))
+			req.NoError(err)
+			tester.Equal([]byte("v"), val)
+		})
+	}
+}
+
+func TestTimeSeriesStore_Snapshot(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			req.NoError(logger.Init(logger.Logging{
+				Env:   "dev",
+				Level: "warn",
+			}))
+			path, deferFn := test.Space(req)
+			defer deferFn()
+			snapshotPath, deferSnapshot := test.Space(req)
+			defer deferSnapshot()
+			open := func(path string, options ...TimeSeriesOptions) TimeSeriesStore {
+				s, err := OpenTimeSeriesStore(0, path, append(options,
+					TSSWithLogger(logger.GetLogger("test")),
+					TSSWithEncoding(encoding.NewPlainEncoderPool(0), encoding.NewPlainDecoderPool(0)))...)
+				req.NoError(err)
+				return s
+			}
+			key := []byte("key")
+			s := open(path, b.tssOpts()...)
+			req.NoError(s.Put(key, []byte("flushed"), 1))
+			if !b.inMemory {
+				req.NoError(s.Close())
+				s = open(path)
+			}
+			defer s.Close()
+			req.NoError(s.Put(key, []byte("in-memory"), 2))
+			req.NoError(s.Snapshot(snapshotPath))
+			req.NoError(s.Put(key, []byte("after"), 3))
+
+			snapshot := open(snapshotPath)
+			defer snapshot.Close()
+			iter, err := snapshot.Scan(key, 0, math.MaxInt64, false)
+			req.NoError(err)
+			defer iter.Close()
+			var vals []string
+			for iter.Next() {
+				vals = append(vals, string(iter.Val()))
+			}
+			tester.Equal([]string{"flushed", "in-memory"}, vals)
+		})
+	}
+}
+
+func TestTimeSeriesStore_DeletePrefix(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			req.NoError(logger.Init(logger.Logging{
+				Env:   "dev",
+				Level: "warn",
+			}))
+			path, deferFn := test.Space(req)
+			defer deferFn()
+			open := func() TimeSeriesStore {
+				s, err := OpenTimeSeriesStore(0, path, b.tssOpts(
+					TSSWithLogger(logger.GetLogger("test")),
+					TSSWithEncoding(encoding.NewPlainEncoderPool(0), encoding.NewPlainDecoderPool(0)))...)
+				req.NoError(err)
+				return s
+			}
+			s := open()
+			req.NoError(s.Put([]byte("a-1"), []byte("flushed"), 1))
+			req.NoError(s.Put([]byte("b-1"), []byte("flushed"), 1))
+			if !b.inMemory {
+				req.NoError(s.Close())
+				s = open()
+			}
+			defer s.Close()
+			req.NoError(s.Put([]byte("a-1"), []byte("in-memory"), 2))
+			req.NoError(s.Put([]byte("a-2"), []byte("in-memory"), 2))
+			req.NoError(s.Put([]byte("b-1"), []byte("in-memory"), 2))
+
+			req.NoError(s.DeletePrefix([]byte("a-")))
+			values := func(key string) (result []string) {
+				iter, err := s.Scan([]byte(key), 0, math.MaxInt64, false)
+				req.NoError(err)
+				defer func() {
+					tester.NoError(iter.Close())
+				}()
+				for iter.Next() {
+					result = append(result, string(iter.Val()))
+				}
+				return result
+			}
+			tester.Empty(values("a-1"))
+			tester.Empty(values("a-2"))
+			tester.Equal([]string{"flushed", "in-memory"}, values("b-1"))
+			req.NoError(s.Put([]byte("a-1"), []byte("after"), 3))
+			tester.Equal([]string{"after"}, values("a-1"), "a deleted key could be put again")
+		})
+	}
+}
+
+func TestTimeSeriesStore_Scan(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			req.NoError(logger.Init(logger.Logging{
+				Env:   "dev",
+				Level: "warn",
+			}))
+			path, deferFn := test.Space(req)
+			defer deferFn()
+			open := func() TimeSeriesStore {
+				s, err := OpenTimeSeriesStore(0, path, b.tssOpts(
+					TSSWithLogger(logger.GetLogger("test")),
+					TSSWithEncoding(encoding.NewPlainEncoderPool(1024), encoding.NewPlainDecoderPool(1024)))...)
+				req.NoError(err)
+				return s
+			}
+			key := []byte("key")
+			put := func(s TimeSeriesStore, points map[uint64]string) {
+				for ts, v := range points {
+					req.NoError(s.Put(key, []byte(v), ts))
+				}
+			}
+			s := open()
+			put(s, map[uint64]string{10: "10", 20: "20", 30: "30"})
+			if !b.inMemory {
+				req.NoError(s.Close())
+				s = open()
+			}
+			// the chunk starting at 15 overlaps the one starting at 10
+			put(s, map[uint64]string{15: "15", 20: "20-new", 25: "25"})
+			if !b.inMemory {
+				req.NoError(s.Close())
+				s = open()
+			}
+			defer s.Close()
+			put(s, map[uint64]string{5: "5", 35: "35"})
+
+			scan := func(startTs, endTs uint64, reverse bool) (result []string) {
+				iter, err := s.Scan(key, startTs, endTs, reverse)
+				req.NoError(err)
+				defer func() {
+					tester.NoError(iter.Close())
+				}()
+				for iter.Next() {
+					tester.True(strings.HasPrefix(string(iter.Val()), strconv.FormatUint(iter.Time(), 10)), "the value should be put at %d", iter.Time())
+					result = append(result, string(iter.Val()))
+				}
+				return result
+			}
+			tester.Equal([]string{"5", "10", "15", "20-new", "25", "30", "35"}, scan(0, math.MaxInt64, false))
+			tester.Equal([]string{"35", "30", "25", "20-new", "15", "10", "5"}, scan(0, math.MaxInt64, true))
+			tester.Equal([]string{"15", "20-new", "25"}, scan(12, 28, false))
+			tester.Equal([]string{"25", "20-new", "15"}, scan(12, 28, true))
+			tester.Empty(scan(28, 12, false))
+		})
+	}
+}
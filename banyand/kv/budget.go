@@ -0,0 +1,127 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kv
+
+import (
+	"sync"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+const (
+	minBlockCacheSize = 1 << 20
+	minMemTableSize   = 1 << 20
+	// budgetNumMemtables is the number of the immutable memory tables a budgeted store keeps
+	budgetNumMemtables = 2
+)
+
+var (
+	defaultBudget   *MemoryBudget
+	defaultBudgetMu sync.RWMutex
+)
+
+// MemoryBudget shares a total block cache size and memory table size among the open stores.
+//
+// Every store opened with the budget takes an equal share among the open stores, bounded by badger's defaults.
+// The open stores keep their shares until they are closed because badger can't resize them,
+// so the budget is a soft limit.
+type MemoryBudget struct {
+	mu             sync.Mutex
+	blockCacheSize int64
+	memTableSize   int64
+	open           int64
+}
+
+// NewMemoryBudget creates a MemoryBudget. A non-positive size leaves the option of badger as it is
+func NewMemoryBudget(blockCacheSize, memTableSize int64) *MemoryBudget {
+	return &MemoryBudget{
+		blockCacheSize: blockCacheSize,
+		memTableSize:   memTableSize,
+	}
+}
+
+// SetDefaultMemoryBudget sets the budget of the stores opened without a budget option. Nil disables it
+func SetDefaultMemoryBudget(b *MemoryBudget) {
+	defaultBudgetMu.Lock()
+	defer defaultBudgetMu.Unlock()
+	defaultBudget = b
+}
+
+func getDefaultMemoryBudget() *MemoryBudget {
+	defaultBudgetMu.RLock()
+	defer defaultBudgetMu.RUnlock()
+	return defaultBudget
+}
+
+// OpenStores returns the number of the stores holding a share of the budget
+func (b *MemoryBudget) OpenStores() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// acquireBudget takes a share of the budget, or of the default one if the budget is nil
+func acquireBudget(b *MemoryBudget, opts badger.Options) (*MemoryBudget, badger.Options) {
+	if b == nil {
+		b = getDefaultMemoryBudget()
+	}
+	if b == nil {
+		return nil, opts
+	}
+	return b, b.acquire(opts)
+}
+
+// acquire sizes the store with an equal share among the stores open at the moment.
+// The shares of the stores opened earlier aren't shrunk, which makes the sum of the shares exceed the budget.
+func (b *MemoryBudget) acquire(opts badger.Options) badger.Options {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.open++
+	if b.blockCacheSize > 0 {
+		opts = opts.WithBlockCacheSize(share(b.blockCacheSize, b.open, minBlockCacheSize, opts.BlockCacheSize))
+	}
+	if b.memTableSize > 0 {
+		// The share covers the mutable table and the immutable ones
+		size := share(b.memTableSize/(budgetNumMemtables+1), b.open, minMemTableSize, opts.MemTableSize)
+		opts = opts.WithMemTableSize(size).WithNumMemtables(budgetNumMemtables)
+		// A value larger than a batch can't be written
+		if maxBatchSize := 15 * size / 100; opts.ValueThreshold > maxBatchSize {
+			opts = opts.WithValueThreshold(maxBatchSize)
+		}
+	}
+	return opts
+}
+
+func (b *MemoryBudget) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open > 0 {
+		b.open--
+	}
+}
+
+func share(total, open, lower, upper int64) int64 {
+	s := total / open
+	if s > upper {
+		s = upper
+	}
+	if s < lower {
+		s = lower
+	}
+	return s
+}
@@ -30,8 +30,10 @@ import (
 )
 
 var (
-	ErrStopScan     = errors.New("stop scanning")
-	DefaultScanOpts = ScanOpts{
+	ErrStopScan         = errors.New("stop scanning")
+	ErrUnsupportedValue = errors.New("the value is not supported")
+	ErrReadOnly         = errors.New("the store is read-only")
+	DefaultScanOpts     = ScanOpts{
 		PrefetchSize:   100,
 		PrefetchValues: true,
 	}
@@ -41,6 +43,50 @@ type Writer interface {
 	// Put a value
 	Put(key, val []byte) error
 	PutWithVersion(key, val []byte, version uint64) error
+	// NewBatch creates a batch whose values are written with versions
+	NewBatch() Batch
+}
+
+// Deleter removes keys. The deleted keys are marked rather than dropped, the compaction reclaims their space.
+type Deleter interface {
+	// Delete all versions of a key
+	Delete(key []byte) error
+	// DeletePrefix deletes all versions of the keys starting with the prefix
+	DeletePrefix(prefix []byte) error
+	// DeleteRange deletes all versions of the keys in [start, end)
+	DeleteRange(start, end []byte) error
+}
+
+// Batch collects values and writes them together.
+// All of them are handed over to the store without waiting for each other, then Commit waits for the results.
+type Batch interface {
+	// Put a value with a version/timestamp into the batch
+	Put(key, val []byte, version uint64)
+	// Len returns the number of the collected values
+	Len() int
+	// Commit writes the collected values and resets the batch
+	Commit() error
+}
+
+// Snapshotter writes a point-in-time copy of a store into a directory, which could be opened as a store as well.
+// The writes should be blocked by the caller till the snapshot is done.
+type Snapshotter interface {
+	Snapshot(dir string) error
+}
+
+// Stats is the statistics of a store
+type Stats struct {
+	// DiskSize is the total size of the files in the store's directory
+	DiskSize int64
+	// KeyCount is the number of distinct keys
+	KeyCount uint64
+	// EntryCount is the number of entries, which counts every version of a key
+	EntryCount uint64
+}
+
+// StatsReporter collects the statistics of a store
+type StatsReporter interface {
+	Stats() Stats
 }
 
 type ScanFunc func(shardID int, key []byte, getVal func() ([]byte, error)) error
@@ -64,7 +110,11 @@ type Reader interface {
 type Store interface {
 	io.Closer
 	Writer
+	Deleter
 	Reader
+	Snapshotter
+	StatsReporter
+	Compactor
 }
 
 type TimeSeriesWriter interface {
@@ -73,6 +123,10 @@ type TimeSeriesWriter interface {
 	// PutAsync a value with a timestamp/version asynchronously.
 	// Injected "f" func will notice the result of value write.
 	PutAsync(key, val []byte, ts uint64, f func(error)) error
+	// NewBatch creates a batch whose values are written with timestamps
+	NewBatch() Batch
+	// DeletePrefix drops all versions of the keys starting with the prefix, the writes are blocked till it's done
+	DeletePrefix(prefix []byte) error
 }
 
 type TimeSeriesReader interface {
@@ -80,6 +134,20 @@ type TimeSeriesReader interface {
 	Get(key []byte, ts uint64) ([]byte, error)
 	// GetAll values with an identical key
 	GetAll(key []byte) ([][]byte, error)
+	// Scan the values of a key whose timestamps/versions are in [startTs, endTs] in the ascending order of the timestamp.
+	// The reverse one is in the descending order.
+	Scan(key []byte, startTs, endTs uint64, reverse bool) (TimeSeriesIterator, error)
+}
+
+// TimeSeriesIterator iterates the values of a key along with their timestamps/versions
+type TimeSeriesIterator interface {
+	// Next moves to the next value, false means there is no more value
+	Next() bool
+	// Time returns the timestamp/version of the current value
+	Time() uint64
+	// Val returns the current value
+	Val() []byte
+	Close() error
 }
 
 // TimeSeriesStore is time series storage
@@ -87,6 +155,9 @@ type TimeSeriesStore interface {
 	io.Closer
 	TimeSeriesWriter
 	TimeSeriesReader
+	Snapshotter
+	StatsReporter
+	Compactor
 }
 
 type TimeSeriesOptions func(TimeSeriesStore)
@@ -115,6 +186,42 @@ func TSSWithEncoding(encoderPool encoding.SeriesEncoderPool, decoderPool encodin
 	}
 }
 
+// TSSWithReadOnly opens the TimeSeriesStore in the read-only mode, whose writes fail
+func TSSWithReadOnly() TimeSeriesOptions {
+	return func(store TimeSeriesStore) {
+		if btss, ok := store.(*badgerTSS); ok {
+			btss.dbOpts = btss.dbOpts.WithReadOnly(true)
+		}
+	}
+}
+
+// TSSWithInMemory keeps the TimeSeriesStore in the memory, whose values are lost after closing
+func TSSWithInMemory() TimeSeriesOptions {
+	return func(store TimeSeriesStore) {
+		if btss, ok := store.(*badgerTSS); ok {
+			btss.dbOpts = inMemory(btss.dbOpts)
+		}
+	}
+}
+
+// TSSWithMemoryBudget shares the block cache and the memory tables of the budget with other stores
+func TSSWithMemoryBudget(budget *MemoryBudget) TimeSeriesOptions {
+	return func(store TimeSeriesStore) {
+		if btss, ok := store.(*badgerTSS); ok {
+			btss.budget = budget
+		}
+	}
+}
+
+// TSSWithMaintenance replaces the default maintenance of the TimeSeriesStore
+func TSSWithMaintenance(m Maintenance) TimeSeriesOptions {
+	return func(store TimeSeriesStore) {
+		if btss, ok := store.(*badgerTSS); ok {
+			btss.maintenance = &m
+		}
+	}
+}
+
 type Iterator interface {
 	Next()
 	Rewind()
@@ -134,6 +241,10 @@ type HandoverCallback func()
 type IndexStore interface {
 	Iterable
 	Reader
+	Deleter
+	Snapshotter
+	StatsReporter
+	Compactor
 	Handover(iterator Iterator) error
 	Close() error
 }
@@ -146,14 +257,24 @@ func OpenTimeSeriesStore(shardID int, path string, options ...TimeSeriesOptions)
 	for _, opt := range options {
 		opt(btss)
 	}
-	// Put all values into LSM
-	btss.dbOpts = btss.dbOpts.WithVLogPercentile(1.0)
+	btss.dbOpts = btss.dbOpts.WithNumVersionsToKeep(math.MaxUint32)
+	btss.budget, btss.dbOpts = acquireBudget(btss.budget, btss.dbOpts)
 	var err error
-	btss.db, err = badger.Open(btss.dbOpts)
+	// The managed mode keeps all values in the LSM tree, and leaves the versions to the writers
+	btss.db, err = badger.OpenManaged(btss.dbOpts)
 	if err != nil {
+		if btss.budget != nil {
+			btss.budget.release()
+		}
 		return nil, fmt.Errorf("failed to open time series store: %v", err)
 	}
-	btss.TSet = *badger.NewTSet(btss.db)
+	// the versions shadowed by the delete markers are discarded by the compaction
+	btss.db.SetDiscardTs(math.MaxUint64)
+	btss.maintainer = startMaintainer(btss.db, btss.maintenance, btss.dbOpts)
+	btss.TSet = *badger.NewTSet(btss.db,
+		badger.WithEncoderPool(btss.dbOpts.EncoderPool),
+		badger.WithDecoderPool(btss.dbOpts.DecoderPool),
+	)
 	return btss, nil
 }
 
@@ -175,6 +296,42 @@ func StoreWithNamedLogger(name string, l *logger.Logger) StoreOptions {
 	}
 }
 
+// StoreWithReadOnly opens the Store in the read-only mode, whose writes fail
+func StoreWithReadOnly() StoreOptions {
+	return func(store Store) {
+		if bdb, ok := store.(*badgerDB); ok {
+			bdb.dbOpts = bdb.dbOpts.WithReadOnly(true)
+		}
+	}
+}
+
+// StoreWithInMemory keeps the Store in the memory, whose values are lost after closing
+func StoreWithInMemory() StoreOptions {
+	return func(store Store) {
+		if bdb, ok := store.(*badgerDB); ok {
+			bdb.dbOpts = inMemory(bdb.dbOpts)
+		}
+	}
+}
+
+// StoreWithMemoryBudget shares the block cache and the memory tables of the budget with other stores
+func StoreWithMemoryBudget(budget *MemoryBudget) StoreOptions {
+	return func(store Store) {
+		if bdb, ok := store.(*badgerDB); ok {
+			bdb.budget = budget
+		}
+	}
+}
+
+// StoreWithMaintenance replaces the default maintenance of the Store
+func StoreWithMaintenance(m Maintenance) StoreOptions {
+	return func(store Store) {
+		if bdb, ok := store.(*badgerDB); ok {
+			bdb.maintenance = &m
+		}
+	}
+}
+
 // OpenStore creates a new Store
 func OpenStore(shardID int, path string, options ...StoreOptions) (Store, error) {
 	bdb := new(badgerDB)
@@ -184,12 +341,19 @@ func OpenStore(shardID int, path string, options ...StoreOptions) (Store, error)
 		opt(bdb)
 	}
 	bdb.dbOpts = bdb.dbOpts.WithNumVersionsToKeep(math.MaxUint32)
+	bdb.budget, bdb.dbOpts = acquireBudget(bdb.budget, bdb.dbOpts)
 
 	var err error
-	bdb.db, err = badger.Open(bdb.dbOpts)
+	// The managed mode keeps all values in the LSM tree, and leaves the versions to the writers
+	bdb.db, err = badger.OpenManaged(bdb.dbOpts)
 	if err != nil {
+		if bdb.budget != nil {
+			bdb.budget.release()
+		}
 		return nil, fmt.Errorf("failed to open normal store: %v", err)
 	}
+	bdb.db.SetDiscardTs(math.MaxUint64)
+	bdb.maintainer = startMaintainer(bdb.db, bdb.maintenance, bdb.dbOpts)
 	return bdb, nil
 }
 
@@ -206,6 +370,42 @@ func IndexWithLogger(l *logger.Logger) IndexOptions {
 	}
 }
 
+// IndexWithReadOnly opens the IndexStore in the read-only mode
+func IndexWithReadOnly() IndexOptions {
+	return func(store IndexStore) {
+		if bdb, ok := store.(*badgerDB); ok {
+			bdb.dbOpts = bdb.dbOpts.WithReadOnly(true)
+		}
+	}
+}
+
+// IndexWithInMemory keeps the IndexStore in the memory, whose values are lost after closing
+func IndexWithInMemory() IndexOptions {
+	return func(store IndexStore) {
+		if bdb, ok := store.(*badgerDB); ok {
+			bdb.dbOpts = inMemory(bdb.dbOpts)
+		}
+	}
+}
+
+// IndexWithMemoryBudget shares the block cache and the memory tables of the budget with other stores
+func IndexWithMemoryBudget(budget *MemoryBudget) IndexOptions {
+	return func(store IndexStore) {
+		if bdb, ok := store.(*badgerDB); ok {
+			bdb.budget = budget
+		}
+	}
+}
+
+// IndexWithMaintenance replaces the default maintenance of the IndexStore
+func IndexWithMaintenance(m Maintenance) IndexOptions {
+	return func(store IndexStore) {
+		if bdb, ok := store.(*badgerDB); ok {
+			bdb.maintenance = &m
+		}
+	}
+}
+
 // OpenIndexStore creates a new IndexStore
 func OpenIndexStore(shardID int, path string, options ...IndexOptions) (IndexStore, error) {
 	bdb := new(badgerDB)
@@ -215,11 +415,18 @@ func OpenIndexStore(shardID int, path string, options ...IndexOptions) (IndexSto
 		opt(bdb)
 	}
 	bdb.dbOpts = bdb.dbOpts.WithNumVersionsToKeep(math.MaxUint32)
+	bdb.budget, bdb.dbOpts = acquireBudget(bdb.budget, bdb.dbOpts)
 
 	var err error
-	bdb.db, err = badger.Open(bdb.dbOpts)
+	// The managed mode keeps all values in the LSM tree, and leaves the versions to the writers
+	bdb.db, err = badger.OpenManaged(bdb.dbOpts)
 	if err != nil {
+		if bdb.budget != nil {
+			bdb.budget.release()
+		}
 		return nil, fmt.Errorf("failed to index store: %v", err)
 	}
+	bdb.db.SetDiscardTs(math.MaxUint64)
+	bdb.maintainer = startMaintainer(bdb.db, bdb.maintenance, bdb.dbOpts)
 	return bdb, nil
 }
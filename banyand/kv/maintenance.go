@@ -0,0 +1,172 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kv
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/dgraph-io/ristretto/z"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultMaintenanceInterval = 10 * time.Minute
+	DefaultDiscardRatio        = 0.5
+)
+
+var (
+	defaultMaintenance = Maintenance{
+		Interval:     DefaultMaintenanceInterval,
+		DiscardRatio: DefaultDiscardRatio,
+	}
+	defaultMaintenanceMu sync.RWMutex
+)
+
+// Maintenance reclaims the space of the deleted and overwritten values.
+//
+// The value log is collected at every interval, a file is rewritten once the DiscardRatio of it can be discarded.
+// A zero Interval disables the periodic collection, but the requested compactions still run.
+type Maintenance struct {
+	Interval     time.Duration
+	DiscardRatio float64
+}
+
+// SetDefaultMaintenance sets the maintenance of the stores opened without a maintenance option
+func SetDefaultMaintenance(m Maintenance) {
+	defaultMaintenanceMu.Lock()
+	defer defaultMaintenanceMu.Unlock()
+	defaultMaintenance = m
+}
+
+func getDefaultMaintenance() Maintenance {
+	defaultMaintenanceMu.RLock()
+	defer defaultMaintenanceMu.RUnlock()
+	return defaultMaintenance
+}
+
+// Compactor compacts a store in the background
+type Compactor interface {
+	// RequestCompaction asks the store to flatten its tables and collect its value log.
+	// It doesn't wait for the compaction, and the requests arriving before the compaction starts are merged.
+	RequestCompaction()
+}
+
+// maintainer runs the maintenance of an open store till it's closed
+type maintainer struct {
+	db        *badger.DB
+	opts      badger.Options
+	m         Maintenance
+	l         badger.Logger
+	requested chan struct{}
+	closer    *z.Closer
+}
+
+// startMaintainer starts the maintenance loop, the read-only stores are left untouched
+func startMaintainer(db *badger.DB, m *Maintenance, opts badger.Options) *maintainer {
+	if opts.ReadOnly {
+		return nil
+	}
+	if m == nil {
+		dm := getDefaultMaintenance()
+		m = &dm
+	}
+	mt := &maintainer{
+		db:        db,
+		opts:      opts,
+		m:         *m,
+		l:         opts.Logger,
+		requested: make(chan struct{}, 1),
+		closer:    z.NewCloser(1),
+	}
+	go mt.run(opts.InMemory)
+	return mt
+}
+
+func (mt *maintainer) run(inMemory bool) {
+	defer mt.closer.Done()
+	var tick <-chan time.Time
+	// There is no value log in the memory
+	if mt.m.Interval > 0 && !inMemory {
+		ticker := time.NewTicker(mt.m.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-mt.closer.HasBeenClosed():
+			return
+		case <-tick:
+			mt.collect()
+		case <-mt.requested:
+			mt.compact()
+			if !inMemory {
+				mt.collect()
+			}
+		}
+	}
+}
+
+// compact flushes the memory tables to include the pending markers, then flattens the tables.
+// The compaction discards the markers along with the versions shadowed by them.
+func (mt *maintainer) compact() {
+	err := flush(mt.db, mt.opts)
+	if err == nil {
+		err = errors.Wrap(mt.db.Flatten(1), "failed to flatten the tables")
+	}
+	if err != nil && mt.l != nil {
+		mt.l.Warningf("failed to compact: %v", err)
+	}
+}
+
+// collect rewrites the value log files till none of them has enough garbage
+func (mt *maintainer) collect() {
+	for {
+		select {
+		case <-mt.closer.HasBeenClosed():
+			return
+		default:
+		}
+		err := mt.db.RunValueLogGC(mt.m.DiscardRatio)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, badger.ErrNoRewrite) && !errors.Is(err, badger.ErrRejected) && mt.l != nil {
+			mt.l.Warningf("failed to collect the value log: %v", err)
+		}
+		return
+	}
+}
+
+func (mt *maintainer) request() {
+	if mt == nil {
+		return
+	}
+	select {
+	case mt.requested <- struct{}{}:
+	default:
+	}
+}
+
+func (mt *maintainer) stop() {
+	if mt == nil {
+		return
+	}
+	mt.closer.SignalAndWait()
+}
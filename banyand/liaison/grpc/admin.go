@@ -0,0 +1,65 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/api/data"
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	"github.com/apache/skywalking-banyandb/banyand/queue"
+	"github.com/apache/skywalking-banyandb/pkg/bus"
+)
+
+var ErrUnsupportedCatalog = errors.New("unsupported catalog")
+
+type adminServer struct {
+	databasev1.UnimplementedAdminServiceServer
+	pipeline queue.Queue
+}
+
+func (as *adminServer) Stats(_ context.Context, req *databasev1.AdminServiceStatsRequest) (*databasev1.AdminServiceStatsResponse, error) {
+	var topic bus.Topic
+	switch req.GetCatalog() {
+	case commonv1.Catalog_CATALOG_STREAM:
+		topic = data.TopicStreamStats
+	case commonv1.Catalog_CATALOG_MEASURE:
+		topic = data.TopicMeasureStats
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedCatalog, "catalog: %s", req.GetCatalog())
+	}
+	feat, err := as.pipeline.Publish(topic, bus.NewMessage(bus.MessageID(time.Now().UnixNano()), req))
+	if err != nil {
+		return nil, err
+	}
+	msg, err := feat.Get()
+	if err != nil {
+		return nil, err
+	}
+	switch d := msg.Data().(type) {
+	case []*databasev1.ShardStats:
+		return &databasev1.AdminServiceStatsResponse{Shards: d}, nil
+	case error:
+		return nil, d
+	}
+	return nil, ErrQueryMsg
+}
@@ -63,6 +63,7 @@ type Server struct {
 	*indexRuleRegistryServer
 	*measureRegistryServer
 	*groupRegistryServer
+	*adminServer
 	streamv1.UnimplementedStreamServiceServer
 }
 
@@ -87,6 +88,9 @@ func NewServer(_ context.Context, pipeline queue.Queue, repo discovery.ServiceRe
 		groupRegistryServer: &groupRegistryServer{
 			schemaRegistry: schemaRegistry,
 		},
+		adminServer: &adminServer{
+			pipeline: pipeline,
+		},
 	}
 }
 
@@ -157,6 +161,7 @@ func (s *Server) Serve() error {
 	databasev1.RegisterIndexRuleRegistryServiceServer(s.ser, s.indexRuleRegistryServer)
 	databasev1.RegisterStreamRegistryServiceServer(s.ser, s.streamRegistryServer)
 	databasev1.RegisterMeasureRegistryServiceServer(s.ser, s.measureRegistryServer)
+	databasev1.RegisterAdminServiceServer(s.ser, s.adminServer)
 
 	s.log.Info().Str("addr", s.addr).Msg("Listening to")
 	return s.ser.Serve(lis)
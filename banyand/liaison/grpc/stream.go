@@ -24,7 +24,6 @@ import (
 
 	"github.com/apache/skywalking-banyandb/api/data"
 	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
-	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/pkg/bus"
 )
 
@@ -46,15 +45,14 @@ func (s *Server) Write(stream streamv1.StreamService_WriteServer) error {
 		if !existed {
 			continue
 		}
-		entity, shardID, err := locator.Locate(writeEntity.GetElement().TagFamilies, shardNum)
+		_, shardID, err := locator.Locate(writeEntity.GetElement().TagFamilies, shardNum)
 		if err != nil {
 			s.log.Error().Err(err).Msg("failed to locate write target")
 			continue
 		}
 		message := bus.NewMessage(bus.MessageID(time.Now().UnixNano()), &streamv1.InternalWriteRequest{
-			Request:    writeEntity,
-			ShardId:    uint32(shardID),
-			SeriesHash: tsdb.HashEntity(entity),
+			Request: writeEntity,
+			ShardId: uint32(shardID),
 		})
 		_, errWritePub := s.pipeline.Publish(data.TopicStreamWrite, message)
 		if errWritePub != nil {
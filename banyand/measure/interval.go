@@ -0,0 +1,125 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package measure
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	measurev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/measure/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/encoding"
+	"github.com/apache/skywalking-banyandb/pkg/partition"
+	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
+)
+
+var ErrInvalidInterval = errors.New("interval is invalid")
+
+// intervalRule aligns the timestamps of the data points whose tag matches the rule to the interval
+type intervalRule struct {
+	familyOffset int
+	tagOffset    int
+	rule         *databasev1.IntervalRule
+	interval     time.Duration
+}
+
+func parseIntervalRules(sm *databasev1.Measure) ([]intervalRule, error) {
+	rules := make([]intervalRule, 0, len(sm.GetIntervalRules()))
+	for _, r := range sm.GetIntervalRules() {
+		fi, ti, spec := pbv1.FindTagByName(sm.GetTagFamilies(), r.GetTagName())
+		if spec == nil {
+			return nil, errors.Wrapf(ErrTagFamilyNotExist, "the tag %s of the interval rule", r.GetTagName())
+		}
+		interval, err := parseInterval(r.GetInterval())
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, intervalRule{
+			familyOffset: fi,
+			tagOffset:    ti,
+			rule:         r,
+			interval:     interval,
+		})
+	}
+	return rules, nil
+}
+
+func (ir intervalRule) match(tagFamilies []*modelv1.TagFamilyForWrite) bool {
+	tag, err := partition.GetTagByOffset(tagFamilies, ir.familyOffset, ir.tagOffset)
+	if err != nil {
+		return false
+	}
+	switch ir.rule.GetTagValue().(type) {
+	case *databasev1.IntervalRule_Str:
+		str, ok := tag.GetValue().(*modelv1.TagValue_Str)
+		return ok && str.Str.GetValue() == ir.rule.GetStr()
+	case *databasev1.IntervalRule_Int:
+		i, ok := tag.GetValue().(*modelv1.TagValue_Int)
+		return ok && i.Int.GetValue() == ir.rule.GetInt()
+	}
+	return false
+}
+
+// alignTime truncates the timestamp to the interval of the first matched rule.
+// The data points written in the same interval share a timestamp and are handled by the duplicate policy.
+func (s *measure) alignTime(value *measurev1.DataPointValue) time.Time {
+	t := value.GetTimestamp().AsTime()
+	for _, ir := range s.intervalRules {
+		if ir.match(value.GetTagFamilies()) {
+			return t.Truncate(ir.interval)
+		}
+	}
+	return t
+}
+
+// intervalOf returns the greatest common divisor of the intervals, on which all the aligned timestamps are.
+// A series might match any rule, so its interval can't be told by its key.
+func intervalOf(rules []intervalRule) encoding.ParseInterval {
+	var interval time.Duration
+	for _, ir := range rules {
+		a, b := interval, ir.interval
+		for b != 0 {
+			a, b = b, a%b
+		}
+		interval = a
+	}
+	return func(_ []byte) time.Duration {
+		return interval
+	}
+}
+
+// parseInterval parses a duration string, a "d" suffix is supported for days besides the ones of time.ParseDuration
+func parseInterval(interval string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if num := strings.TrimSuffix(interval, "d"); num != interval {
+		var days int64
+		days, err = strconv.ParseInt(num, 10, 64)
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(interval)
+	}
+	if err != nil || d <= 0 {
+		return 0, errors.Wrapf(ErrInvalidInterval, "interval: %s", interval)
+	}
+	return d, nil
+}
@@ -19,6 +19,10 @@ package measure
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/multierr"
 
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
@@ -31,6 +35,13 @@ import (
 // a chunk is 1MB
 const chunkSize = 1 << 20
 
+// dataRootTemplate is the location of the data of a measure, which is <root>/measure/data/<group>/<name>.
+// The catalog is a part of the path since the streams and the measures share the root path.
+const dataRootTemplate = "%s/measure/data/%s/%s"
+
+// a chunk of an int field has 1024 points at most
+const intChunkSize = 1 << 10
+
 type measure struct {
 	name          string
 	group         string
@@ -39,12 +50,18 @@ type measure struct {
 	db            tsdb.Database
 	entityLocator partition.EntityLocator
 	indexRules    []*databasev1.IndexRule
+	intervalRules []intervalRule
 	indexWriter   *index.Writer
+	wal           *tsdb.WAL
 }
 
 func (s *measure) Close() error {
 	_ = s.indexWriter.Close()
-	return s.db.Close()
+	err := s.db.Close()
+	if s.wal != nil {
+		err = multierr.Append(err, s.wal.Close())
+	}
+	return err
 }
 
 func (s *measure) parseSchema() {
@@ -57,6 +74,17 @@ func (s *measure) parseSchema() {
 type measureSpec struct {
 	schema     *databasev1.Measure
 	indexRules []*databasev1.IndexRule
+	// retentionInterval is the interval of removing the data beyond the ttl, zero disables the retention
+	retentionInterval time.Duration
+	// backfillWindow limits how far in the past the data could be written, zero means no limit
+	backfillWindow time.Duration
+	// walSyncWrites flushes every entry of the write-ahead log to the disk before acknowledging the write
+	walSyncWrites bool
+	// coldRoot is the location of the segments older than coldAge, empty disables the tiering
+	coldRoot string
+	coldAge  time.Duration
+	// tieringInterval is the interval of moving the aged segments to coldRoot
+	tieringInterval time.Duration
 }
 
 func openMeasure(root string, spec measureSpec, l *logger.Logger) (*measure, error) {
@@ -66,19 +94,40 @@ func openMeasure(root string, spec measureSpec, l *logger.Logger) (*measure, err
 		l:          l,
 	}
 	sm.parseSchema()
+	intervalRules, err := parseIntervalRules(sm.schema)
+	if err != nil {
+		return nil, err
+	}
+	sm.intervalRules = intervalRules
+	gorillaFields := gorillaFieldSelector(sm.schema)
 	ctx := context.WithValue(context.Background(), logger.ContextKey, l)
 
-	db, err := tsdb.OpenDatabase(
-		ctx,
-		tsdb.DatabaseOpts{
-			Location:   root,
-			ShardNum:   sm.schema.GetOpts().GetShardNum(),
-			IndexRules: spec.indexRules,
-			EncodingMethod: tsdb.EncodingMethod{
-				EncoderPool: encoding.NewPlainEncoderPool(chunkSize),
-				DecoderPool: encoding.NewPlainDecoderPool(chunkSize),
-			},
-		})
+	meta := spec.schema.GetMetadata()
+	opts := tsdb.DatabaseOpts{
+		Location:   fmt.Sprintf(dataRootTemplate, root, meta.GetGroup(), meta.GetName()),
+		ShardNum:   sm.schema.GetOpts().GetShardNum(),
+		IndexRules: spec.indexRules,
+		EncodingMethod: tsdb.EncodingMethod{
+			EncoderPool: encoding.NewSelectiveEncoderPool(gorillaFields,
+				encoding.NewIntEncoderPool(intChunkSize, intervalOf(intervalRules)), encoding.NewPlainEncoderPool(chunkSize)),
+			DecoderPool: encoding.NewSelectiveDecoderPool(gorillaFields,
+				encoding.NewIntDecoderPool(intChunkSize), encoding.NewPlainDecoderPool(chunkSize)),
+		},
+		TTL:               tsdb.NewTTL(sm.schema.GetOpts().GetTtl()),
+		RetentionInterval: spec.retentionInterval,
+		BackfillWindow:    spec.backfillWindow,
+		DuplicatePolicy:   sm.schema.GetOpts().GetDuplicatePolicy(),
+		InMemory:          sm.schema.GetOpts().GetInMemory(),
+		ColdAge:           spec.coldAge,
+		TieringInterval:   spec.tieringInterval,
+	}
+	if spec.coldRoot != "" {
+		opts.ColdLocation = fmt.Sprintf(dataRootTemplate, spec.coldRoot, meta.GetGroup(), meta.GetName())
+	}
+	if err := tsdb.Reshard(ctx, opts, sm.reshardOpts()); err != nil {
+		return nil, err
+	}
+	db, err := tsdb.OpenDatabase(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -89,5 +138,13 @@ func openMeasure(root string, spec measureSpec, l *logger.Logger) (*measure, err
 		Families:   spec.schema.TagFamilies,
 		IndexRules: spec.indexRules,
 	})
+	// the data in the memory needn't be replayed
+	if opts.InMemory {
+		return sm, nil
+	}
+	if err = sm.openWAL(ctx, root, spec.walSyncWrites); err != nil {
+		_ = sm.Close()
+		return nil, err
+	}
 	return sm, nil
 }
@@ -0,0 +1,271 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package measure
+
+import (
+	"time"
+
+	"github.com/dgraph-io/ristretto/z"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	measurev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/measure/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/banyand/kv"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+)
+
+var ErrIncompatibleRollupTarget = errors.New("the target of the rollup doesn't share the tag families and the fields with the source")
+
+// rollup aggregates the data points of the source in every interval into a data point of the target.
+// The aggregated data point of a series takes the start of the interval as its timestamp.
+type rollup struct {
+	source   *measure
+	target   *measure
+	interval time.Duration
+	function databasev1.RollupFunction
+	// next is the start of the interval to be aggregated
+	next   time.Time
+	closer *z.Closer
+	l      *logger.Logger
+}
+
+func newRollup(source, target *measure, rule *databasev1.RollupRule, l *logger.Logger) (*rollup, error) {
+	if !compatible(source.schema, target.schema) {
+		return nil, errors.Wrapf(ErrIncompatibleRollupTarget, "source: %s, target: %s", source.name, target.name)
+	}
+	interval, err := parseInterval(rule.GetInterval())
+	if err != nil {
+		return nil, err
+	}
+	return &rollup{
+		source:   source,
+		target:   target,
+		interval: interval,
+		function: rule.GetFunction(),
+		next:     time.Now().Truncate(interval),
+		l:        l.Named("rollup"),
+	}, nil
+}
+
+func compatible(source, target *databasev1.Measure) bool {
+	if len(source.GetTagFamilies()) != len(target.GetTagFamilies()) || len(source.GetFields()) != len(target.GetFields()) {
+		return false
+	}
+	for i, f := range source.GetTagFamilies() {
+		if !proto.Equal(f, target.GetTagFamilies()[i]) {
+			return false
+		}
+	}
+	for i, f := range source.GetFields() {
+		if !proto.Equal(f, target.GetFields()[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *rollup) start() {
+	r.closer = z.NewCloser(1)
+	go func() {
+		defer r.closer.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		r.l.Info().Str("source", r.source.name).Str("target", r.target.name).Dur("interval", r.interval).Msg("started")
+		for {
+			select {
+			case <-r.closer.HasBeenClosed():
+				return
+			case now := <-ticker.C:
+				r.aggregateUntil(now)
+			}
+		}
+	}()
+}
+
+// aggregateUntil aggregates all the intervals ended before now, which is tolerant of the delayed ticks
+func (r *rollup) aggregateUntil(now time.Time) {
+	for end := r.next.Add(r.interval); !end.After(now); end = r.next.Add(r.interval) {
+		if err := r.aggregate(r.next); err != nil {
+			r.l.Error().Err(err).Time("start", r.next).Msg("failed to roll up the data points")
+		}
+		r.next = end
+	}
+}
+
+func (r *rollup) aggregate(start time.Time) error {
+	timeRange := tsdb.NewTimeRangeDuration(start, r.interval)
+	path := tsdb.NewPath(make([]tsdb.Entry, len(r.source.entityLocator)))
+	for _, shard := range r.source.db.Shards() {
+		seriesList, err := shard.Series().List(path)
+		if err != nil {
+			return err
+		}
+		for _, series := range seriesList {
+			value, err := r.aggregateSeries(series, timeRange)
+			if errors.Is(err, tsdb.ErrEmptySeriesSpan) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				continue
+			}
+			value.Timestamp = timestamppb.New(start)
+			if err = r.target.Write(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *rollup) aggregateSeries(series tsdb.Series, timeRange tsdb.TimeRange) (*measurev1.DataPointValue, error) {
+	span, err := series.Span(timeRange)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = span.Close()
+	}()
+	seeker, err := span.SeekerBuilder().OrderByTime(modelv1.Sort_SORT_ASC).Build()
+	if err != nil {
+		return nil, err
+	}
+	iters, err := seeker.Seek()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, iter := range iters {
+			_ = iter.Close()
+		}
+	}()
+	fieldSpecs := r.source.schema.GetFields()
+	aggregators := make([]fieldAggregator, len(fieldSpecs))
+	var value *measurev1.DataPointValue
+	var latest uint64
+	for _, iter := range iters {
+		for iter.Next() {
+			item := iter.Val()
+			if value == nil || item.Time() >= latest {
+				latest = item.Time()
+				if value, err = r.readTagFamilies(item); err != nil {
+					return nil, err
+				}
+			}
+			for i, spec := range fieldSpecs {
+				field, errField := r.source.ParseField(spec.GetName(), item)
+				if errors.Is(errField, kv.ErrKeyNotFound) {
+					continue
+				}
+				if errField != nil {
+					return nil, errField
+				}
+				aggregators[i].add(item.Time(), field.GetValue())
+			}
+		}
+	}
+	if value == nil {
+		return nil, nil
+	}
+	value.Fields = make([]*modelv1.FieldValue, len(aggregators))
+	for i := range aggregators {
+		value.Fields[i] = aggregators[i].result(r.function)
+	}
+	return value, nil
+}
+
+func (r *rollup) readTagFamilies(item tsdb.Item) (*measurev1.DataPointValue, error) {
+	tagFamilies := r.source.schema.GetTagFamilies()
+	value := &measurev1.DataPointValue{
+		TagFamilies: make([]*modelv1.TagFamilyForWrite, len(tagFamilies)),
+	}
+	for i, f := range tagFamilies {
+		tagFamily, err := r.source.readTagFamily(f.GetName(), item)
+		if err != nil {
+			return nil, err
+		}
+		value.TagFamilies[i] = tagFamily
+	}
+	return value, nil
+}
+
+func (r *rollup) stop() {
+	// it isn't started if the service doesn't serve
+	if r.closer != nil {
+		r.closer.SignalAndWait()
+	}
+}
+
+// fieldAggregator aggregates the int values of a field, and keeps the latest value of the other types
+type fieldAggregator struct {
+	count      int64
+	sum        int64
+	max        int64
+	min        int64
+	latestTime uint64
+	latest     *modelv1.FieldValue
+}
+
+func (fa *fieldAggregator) add(t uint64, value *modelv1.FieldValue) {
+	if fa.latest == nil || t >= fa.latestTime {
+		fa.latestTime = t
+		fa.latest = value
+	}
+	iv, ok := value.GetValue().(*modelv1.FieldValue_Int)
+	if !ok {
+		return
+	}
+	v := iv.Int.GetValue()
+	if fa.count == 0 || v > fa.max {
+		fa.max = v
+	}
+	if fa.count == 0 || v < fa.min {
+		fa.min = v
+	}
+	fa.sum += v
+	fa.count++
+}
+
+func (fa *fieldAggregator) result(function databasev1.RollupFunction) *modelv1.FieldValue {
+	if fa.latest == nil {
+		return &modelv1.FieldValue{Value: &modelv1.FieldValue_Null{}}
+	}
+	if fa.count == 0 {
+		return fa.latest
+	}
+	var v int64
+	switch function {
+	case databasev1.RollupFunction_ROLLUP_FUNCTION_MEAN:
+		v = fa.sum / fa.count
+	case databasev1.RollupFunction_ROLLUP_FUNCTION_MAX:
+		v = fa.max
+	case databasev1.RollupFunction_ROLLUP_FUNCTION_MIN:
+		v = fa.min
+	case databasev1.RollupFunction_ROLLUP_FUNCTION_LAST:
+		return fa.latest
+	default:
+		v = fa.sum
+	}
+	return &modelv1.FieldValue{Value: &modelv1.FieldValue_Int{Int: &modelv1.Int{Value: v}}}
+}
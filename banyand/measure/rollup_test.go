@@ -0,0 +1,134 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package measure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/test"
+)
+
+func Test_Measure_Rollup(t *testing.T) {
+	s, deferFunc := setup(t)
+	defer deferFunc()
+	baseTime := writeData(t, "query_data.json", s)
+	r := require.New(t)
+	tempDir, deferSpace := test.Space(r)
+	defer deferSpace()
+	targetSchema := proto.Clone(s.schema).(*databasev1.Measure)
+	targetSchema.Metadata.Name = "cpm_hour"
+	target, err := openMeasure(tempDir, measureSpec{
+		schema:     targetSchema,
+		indexRules: s.indexRules,
+	}, logger.GetLogger("test"))
+	r.NoError(err)
+	defer func() {
+		_ = target.Close()
+	}()
+	ru, err := newRollup(s, target, &databasev1.RollupRule{
+		Target:   targetSchema.GetMetadata(),
+		Interval: "1h",
+		Function: databasev1.RollupFunction_ROLLUP_FUNCTION_SUM,
+	}, s.l)
+	r.NoError(err)
+	// the data points might spread over two hours
+	start := baseTime.Truncate(time.Hour)
+	ru.next = start
+	ru.aggregateUntil(start.Add(2 * time.Hour))
+	r.Equal(start.Add(2*time.Hour), ru.next)
+
+	shard, err := target.Shard(0)
+	r.NoError(err)
+	series, err := shard.Series().Get(tsdb.Entity{tsdb.Entry("1")})
+	r.NoError(err)
+	seriesSpan, err := series.Span(tsdb.NewTimeRangeDuration(start, 2*time.Hour))
+	r.NoError(err)
+	defer func() {
+		_ = seriesSpan.Close()
+	}()
+	seeker, err := seriesSpan.SeekerBuilder().OrderByTime(modelv1.Sort_SORT_ASC).Build()
+	r.NoError(err)
+	iters, err := seeker.Seek()
+	r.NoError(err)
+	sums := make([]int64, 3)
+	for _, iter := range iters {
+		for iter.Next() {
+			item := iter.Val()
+			at := time.Unix(0, int64(item.Time()))
+			assert.True(t, at.Equal(at.Truncate(time.Hour)))
+			tagFamily, errTag := target.ParseTagFamily("default", item)
+			r.NoError(errTag)
+			assert.Equal(t, "1", tagFamily.GetTags()[0].GetValue().GetStr().GetValue())
+			for i, name := range []string{"summation", "count", "value"} {
+				field, errField := target.ParseField(name, item)
+				r.NoError(errField)
+				sums[i] += field.GetValue().GetInt().GetValue()
+			}
+		}
+		_ = iter.Close()
+	}
+	assert.Equal(t, []int64{450, 450, 10}, sums)
+}
+
+func Test_ParseInterval(t *testing.T) {
+	tests := []struct {
+		interval string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{interval: "1m", want: time.Minute},
+		{interval: "1h", want: time.Hour},
+		{interval: "1d", want: 24 * time.Hour},
+		{interval: "0s", wantErr: true},
+		{interval: "d", wantErr: true},
+		{interval: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			got, err := parseInterval(tt.interval)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInterval)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_IntervalOf(t *testing.T) {
+	rules := func(intervals ...time.Duration) (result []intervalRule) {
+		for _, i := range intervals {
+			result = append(result, intervalRule{interval: i})
+		}
+		return result
+	}
+	assert.Equal(t, time.Duration(0), intervalOf(nil)(nil))
+	assert.Equal(t, time.Minute, intervalOf(rules(time.Minute))(nil))
+	assert.Equal(t, 5*time.Minute, intervalOf(rules(time.Hour, 5*time.Minute, 15*time.Minute))(nil))
+	assert.Equal(t, 2*time.Minute, intervalOf(rules(4*time.Minute, 6*time.Minute))(nil))
+}
@@ -0,0 +1,36 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package measure
+
+import (
+	"github.com/pkg/errors"
+
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+)
+
+func setUpStatsCallback(l *logger.Logger, schemaMap map[string]*measure) *tsdb.StatsCallback {
+	return tsdb.NewStatsCallback(l, func(metadata *commonv1.Metadata) (tsdb.Database, error) {
+		sm, ok := schemaMap[formatMeasureID(metadata.GetName(), metadata.GetGroup())]
+		if !ok {
+			return nil, errors.WithStack(ErrMeasureNotExist)
+		}
+		return sm.db, nil
+	})
+}
@@ -0,0 +1,52 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package measure
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+
+	measurev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/measure/v1"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+)
+
+// openWAL replays the write-ahead logs of the measure, whose entries are routed with the current shard number
+func (s *measure) openWAL(ctx context.Context, root string, syncWrites bool) (err error) {
+	shardNum := s.schema.GetOpts().GetShardNum()
+	s.wal, err = tsdb.OpenWAL(ctx, tsdb.WALOpts{
+		Root:       root,
+		Catalog:    "measure",
+		Metadata:   s.schema.GetMetadata(),
+		ShardNum:   shardNum,
+		SyncWrites: syncWrites,
+		Replay: func(data []byte, source []byte, cb func()) error {
+			req := &measurev1.InternalWriteRequest{}
+			if errUnmarshal := proto.Unmarshal(data, req); errUnmarshal != nil {
+				return errUnmarshal
+			}
+			value := req.GetRequest().GetDataPoint()
+			entity, shardID, errLocate := s.entityLocator.Locate(value.GetTagFamilies(), shardNum)
+			if errLocate != nil {
+				return errLocate
+			}
+			return s.apply(shardID, entity, value, source, cb)
+		},
+	})
+	return err
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/apache/skywalking-banyandb/banyand/tsdb/index"
 	"github.com/apache/skywalking-banyandb/pkg/bus"
 	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/encoding"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
 )
@@ -49,7 +50,7 @@ func (s *measure) Write(value *measurev1.DataPointValue) error {
 		return err
 	}
 	waitCh := make(chan struct{})
-	err = s.write(shardID, tsdb.HashEntity(entity), value, func() {
+	err = s.write(shardID, entity, value, func() {
 		close(waitCh)
 	})
 	if err != nil {
@@ -60,7 +61,34 @@ func (s *measure) Write(value *measurev1.DataPointValue) error {
 	return nil
 }
 
-func (s *measure) write(shardID common.ShardID, seriesHashKey []byte, value *measurev1.DataPointValue, cb index.CallbackFn) error {
+// write records the value in the write-ahead log of the shard before applying it.
+// The entry is done once both the data and the indices are written. The value kept in the memory isn't logged.
+func (s *measure) write(shardID common.ShardID, entity tsdb.Entity, value *measurev1.DataPointValue, cb index.CallbackFn) error {
+	if s.schema.GetOpts().GetInMemory() {
+		return s.apply(shardID, entity, value, nil, cb)
+	}
+	data, err := proto.Marshal(&measurev1.InternalWriteRequest{
+		ShardId: uint32(shardID),
+		Request: &measurev1.WriteRequest{
+			Metadata:  s.schema.GetMetadata(),
+			DataPoint: value,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return s.wal.Write(shardID, data, func(source []byte, done func()) error {
+		return s.apply(shardID, entity, value, source, func() {
+			done()
+			if cb != nil {
+				cb()
+			}
+		})
+	})
+}
+
+// apply writes the value into the database, the source identifies the write-ahead log entry of the value
+func (s *measure) apply(shardID common.ShardID, entity tsdb.Entity, value *measurev1.DataPointValue, source []byte, cb index.CallbackFn) error {
 	sm := s.schema
 	fLen := len(value.GetTagFamilies())
 	if fLen < 1 {
@@ -73,20 +101,24 @@ func (s *measure) write(shardID common.ShardID, seriesHashKey []byte, value *mea
 	if err != nil {
 		return err
 	}
-	series, err := shard.Series().GetByHashKey(seriesHashKey)
+	series, err := shard.Series().Get(entity)
 	if err != nil {
 		return err
 	}
-	t := value.GetTimestamp().AsTime()
-	wp, err := series.Span(tsdb.NewTimeRangeDuration(t, 0))
+	t := s.alignTime(value)
+	wp, err := series.Create(t)
 	if err != nil {
 		if wp != nil {
 			_ = wp.Close()
 		}
 		return err
 	}
+	columnar := sm.GetOpts().GetTagStorageLayout() == databasev1.TagStorageLayout_TAG_STORAGE_LAYOUT_COLUMNAR
 	writeFn := func() (tsdb.Writer, error) {
 		builder := wp.WriterBuilder().Time(t)
+		if source != nil {
+			builder.Source(source)
+		}
 		for fi, family := range value.GetTagFamilies() {
 			familySpec := sm.GetTagFamilies()[fi]
 			if len(family.GetTags()) > len(familySpec.GetTags()) {
@@ -101,6 +133,17 @@ func (s *measure) write(shardID common.ShardID, seriesHashKey []byte, value *mea
 				if tType != tagSpec.GetType() {
 					return nil, errors.Wrapf(ErrMalformedElement, "tag %s type is unexpected", tagSpec.GetName())
 				}
+				if !columnar {
+					continue
+				}
+				bb, errMarshal := proto.Marshal(tag)
+				if errMarshal != nil {
+					return nil, errMarshal
+				}
+				builder.Family(tagIdentity(familySpec.GetName(), tagSpec.GetName()), bb)
+			}
+			if columnar {
+				continue
 			}
 			bb, errMarshal := proto.Marshal(family)
 			if errMarshal != nil {
@@ -149,7 +192,7 @@ func (s *measure) write(shardID common.ShardID, seriesHashKey []byte, value *mea
 		LocalWriter: writer,
 		Value: index.Value{
 			TagFamilies: value.GetTagFamilies(),
-			Timestamp:   value.GetTimestamp().AsTime(),
+			Timestamp:   t,
 		},
 		BlockCloser: wp,
 		Cb:          cb,
@@ -179,9 +222,21 @@ func (w *writeCallback) Rev(message bus.Message) (resp bus.Message) {
 	}
 	sm := writeEvent.GetRequest().GetMetadata()
 	id := formatMeasureID(sm.GetName(), sm.GetGroup())
-	err := w.schemaMap[id].write(common.ShardID(writeEvent.GetShardId()), writeEvent.GetSeriesHash(), writeEvent.GetRequest().GetDataPoint(), nil)
+	s := w.schemaMap[id]
+	value := writeEvent.GetRequest().GetDataPoint()
+	// the entity is stored along with the series, so it's found from the tags instead of the hash key
+	entity, err := s.entityLocator.Find(value.GetTagFamilies())
 	if err != nil {
-		w.l.Debug().Err(err)
+		w.l.Error().Err(err).Msg("failed to find the entity")
+		return
+	}
+	err = s.write(common.ShardID(writeEvent.GetShardId()), entity, value, nil)
+	if errors.Is(err, tsdb.ErrDuplicatedItem) {
+		w.l.Warn().Err(err).Msg("reject the duplicated data point")
+		return
+	}
+	if err != nil {
+		w.l.Error().Err(err).Msg("failed to write the data point")
 	}
 	return
 }
@@ -190,6 +245,11 @@ func familyIdentity(name string, flag byte) []byte {
 	return bytes.Join([][]byte{[]byte(name), {flag}}, nil)
 }
 
+// tagIdentity is the identity of the column storing a tag in the columnar layout
+func tagIdentity(family, tag string) []byte {
+	return bytes.Join([][]byte{familyIdentity(family, TagFlag), []byte(tag)}, nil)
+}
+
 func encodeFieldValue(fieldValue *modelv1.FieldValue) []byte {
 	switch fieldValue.GetValue().(type) {
 	case *modelv1.FieldValue_Int:
@@ -214,6 +274,26 @@ func decodeFieldValue(fieldValue []byte, fieldSpec *databasev1.FieldSpec) *model
 	return &modelv1.FieldValue{Value: &modelv1.FieldValue_Null{}}
 }
 
+// gorillaFieldSelector picks the keys of the int fields encoded by gorilla, other fields are encoded plainly.
+// The key of a field ends with the hash of its family identity, which differs once the encoding method changes.
+func gorillaFieldSelector(sm *databasev1.Measure) encoding.KeySelector {
+	hashes := make(map[uint64]struct{})
+	for _, f := range sm.GetFields() {
+		if f.GetFieldType() == databasev1.FieldType_FIELD_TYPE_INT &&
+			f.GetEncodingMethod() == databasev1.EncodingMethod_ENCODING_METHOD_GORILLA {
+			hashes[convert.Hash(familyIdentity(f.GetName(), encoderFieldFlag(f)))] = struct{}{}
+		}
+	}
+	return func(key []byte) bool {
+		// the key of the default family is the series id alone
+		if len(hashes) == 0 || len(key) <= 8 {
+			return false
+		}
+		_, ok := hashes[convert.BytesToUint64(key[len(key)-8:])]
+		return ok
+	}
+}
+
 func encoderFieldFlag(fieldSpec *databasev1.FieldSpec) byte {
 	encodingMethod := byte(fieldSpec.GetEncodingMethod().Number())
 	compressionMethod := byte(fieldSpec.GetCompressionMethod().Number())
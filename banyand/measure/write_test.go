@@ -31,8 +31,12 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	measurev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/measure/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	"github.com/apache/skywalking-banyandb/banyand/metadata"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 	"github.com/apache/skywalking-banyandb/pkg/test"
 	testmeasure "github.com/apache/skywalking-banyandb/pkg/test/measure"
@@ -93,7 +97,8 @@ func writeData(testing *testing.T, dataFile string, measure *measure) (baseTime
 	t := assert.New(testing)
 	r := require.New(testing)
 	var templates []interface{}
-	baseTime = time.Now()
+	// the data points are aligned to the minute by the interval rule
+	baseTime = time.Now().Truncate(time.Minute)
 	content, err := dataFS.ReadFile("testdata/" + dataFile)
 	r.NoError(err)
 	r.NoError(json.Unmarshal(content, &templates))
@@ -111,3 +116,85 @@ func writeData(testing *testing.T, dataFile string, measure *measure) (baseTime
 	}
 	return baseTime
 }
+
+func Test_Measure_Write_Interval(t *testing.T) {
+	s, deferFunc := setup(t)
+	defer deferFunc()
+	r := require.New(t)
+	baseTime := time.Now().Truncate(time.Minute)
+	for i, v := range []int64{1, 2} {
+		r.NoError(s.Write(&measurev1.DataPointValue{
+			Timestamp: timestamppb.New(baseTime.Add(time.Duration(i+1) * 10 * time.Second)),
+			TagFamilies: []*modelv1.TagFamilyForWrite{
+				{
+					Tags: []*modelv1.TagValue{
+						{Value: &modelv1.TagValue_Str{Str: &modelv1.Str{Value: "1"}}},
+						{Value: &modelv1.TagValue_Str{Str: &modelv1.Str{Value: "minute"}}},
+					},
+				},
+			},
+			Fields: []*modelv1.FieldValue{
+				{Value: &modelv1.FieldValue_Int{Int: &modelv1.Int{Value: v}}},
+			},
+		}))
+	}
+	shard, err := s.Shard(0)
+	r.NoError(err)
+	series, err := shard.Series().Get(tsdb.Entity{tsdb.Entry("1")})
+	r.NoError(err)
+	seriesSpan, err := series.Span(tsdb.NewTimeRangeDuration(baseTime, time.Minute))
+	r.NoError(err)
+	defer func() {
+		_ = seriesSpan.Close()
+	}()
+	seeker, err := seriesSpan.SeekerBuilder().OrderByTime(modelv1.Sort_SORT_DESC).Build()
+	r.NoError(err)
+	iters, err := seeker.Seek()
+	r.NoError(err)
+	r.Equal(1, len(iters))
+	defer func() {
+		_ = iters[0].Close()
+	}()
+	var times []uint64
+	var values []int64
+	for iters[0].Next() {
+		item := iters[0].Val()
+		times = append(times, item.Time())
+		summation, errField := s.ParseField("summation", item)
+		r.NoError(errField)
+		values = append(values, summation.GetValue().GetInt().GetValue())
+	}
+	// the later one overwrites the former one in the same minute
+	assert.Equal(t, []uint64{uint64(baseTime.UnixNano())}, times)
+	assert.Equal(t, []int64{2}, values)
+}
+
+func Test_GorillaFieldSelector(t *testing.T) {
+	sm := &databasev1.Measure{
+		Fields: []*databasev1.FieldSpec{
+			{
+				Name:           "gorilla",
+				FieldType:      databasev1.FieldType_FIELD_TYPE_INT,
+				EncodingMethod: databasev1.EncodingMethod_ENCODING_METHOD_GORILLA,
+			},
+			{
+				Name:      "plain",
+				FieldType: databasev1.FieldType_FIELD_TYPE_INT,
+			},
+			{
+				Name:           "str",
+				FieldType:      databasev1.FieldType_FIELD_TYPE_STRING,
+				EncodingMethod: databasev1.EncodingMethod_ENCODING_METHOD_GORILLA,
+			},
+		},
+	}
+	selector := gorillaFieldSelector(sm)
+	seriesID := convert.Uint64ToBytes(1)
+	key := func(f *databasev1.FieldSpec) []byte {
+		return append(seriesID, convert.Uint64ToBytes(convert.Hash(familyIdentity(f.GetName(), encoderFieldFlag(f))))...)
+	}
+	assert.True(t, selector(key(sm.GetFields()[0])))
+	assert.False(t, selector(key(sm.GetFields()[1])))
+	assert.False(t, selector(key(sm.GetFields()[2])), "only int fields are encoded by gorilla")
+	assert.False(t, selector(seriesID))
+}
@@ -0,0 +1,46 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package stream
+
+import (
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb/index"
+)
+
+// reshardOpts copies the element id and the tag families, and rebuilds the indices from the tag families
+func (s *stream) reshardOpts() tsdb.ReshardOpts {
+	tagFamilies := s.schema.GetTagFamilies()
+	families := make([][]byte, 0, len(tagFamilies)+1)
+	families = append(families, nil)
+	for _, f := range tagFamilies {
+		if !s.columnar() {
+			families = append(families, []byte(f.GetName()))
+			continue
+		}
+		for _, t := range f.GetTags() {
+			families = append(families, tagIdentity(f.GetName(), t.GetName()))
+		}
+	}
+	return index.NewReshardOpts(s.l, index.ReshardOptions{
+		ShardNum:       s.schema.GetOpts().GetShardNum(),
+		Families:       tagFamilies,
+		IndexRules:     s.indexRules,
+		CopiedFamilies: families,
+		ReadTagFamily:  s.readTagFamily,
+	})
+}
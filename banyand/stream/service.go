@@ -32,6 +32,7 @@ import (
 	"github.com/apache/skywalking-banyandb/banyand/metadata"
 	"github.com/apache/skywalking-banyandb/banyand/metadata/schema"
 	"github.com/apache/skywalking-banyandb/banyand/queue"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/pkg/bus"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 	"github.com/apache/skywalking-banyandb/pkg/run"
@@ -52,14 +53,21 @@ type Service interface {
 var _ Service = (*service)(nil)
 
 type service struct {
-	schemaMap     map[string]*stream
-	writeListener *writeCallback
-	l             *logger.Logger
-	metadata      metadata.Repo
-	root          string
-	pipeline      queue.Queue
-	repo          discovery.ServiceRepo
-	stopCh        chan struct{}
+	schemaMap         map[string]*stream
+	writeListener     *writeCallback
+	statsListener     *tsdb.StatsCallback
+	l                 *logger.Logger
+	metadata          metadata.Repo
+	root              string
+	retentionInterval time.Duration
+	backfillWindow    time.Duration
+	walSyncWrites     bool
+	coldRoot          string
+	coldAge           time.Duration
+	tieringInterval   time.Duration
+	pipeline          queue.Queue
+	repo              discovery.ServiceRepo
+	stopCh            chan struct{}
 }
 
 func (s *service) Stream(stream *commonv1.Metadata) (Stream, error) {
@@ -74,6 +82,12 @@ func (s *service) Stream(stream *commonv1.Metadata) (Stream, error) {
 func (s *service) FlagSet() *run.FlagSet {
 	flagS := run.NewFlagSet("storage")
 	flagS.StringVar(&s.root, "root-path", "/tmp", "the root path of database")
+	flagS.DurationVar(&s.retentionInterval, "retention-interval", time.Hour, "the interval of removing the data beyond the ttl, 0 disables the retention")
+	flagS.DurationVar(&s.backfillWindow, "backfill-window", 7*24*time.Hour, "how far in the past the data could be written, 0 means no limit")
+	flagS.BoolVar(&s.walSyncWrites, "wal-sync-writes", false, "flush every entry of the write-ahead log to the disk before acknowledging the write")
+	flagS.StringVar(&s.coldRoot, "cold-root-path", "", "the root path of the aged segments, which are read-only. Empty disables the tiered storage")
+	flagS.DurationVar(&s.coldAge, "cold-age", 3*24*time.Hour, "how long a segment stays in the root path before moving to the cold root path")
+	flagS.DurationVar(&s.tieringInterval, "tiering-interval", time.Hour, "the interval of moving the aged segments to the cold root path")
 	return flagS
 }
 
@@ -96,14 +110,28 @@ func (s *service) PreRun() error {
 
 	s.schemaMap = make(map[string]*stream, len(schemas))
 	s.l = logger.GetLogger(s.Name())
+	for _, root := range []string{s.root, s.coldRoot} {
+		if root == "" {
+			continue
+		}
+		if err = tsdb.CheckLayout(root); err != nil {
+			return err
+		}
+	}
 	for _, sa := range schemas {
 		iRules, errIndexRules := s.metadata.IndexRules(context.TODO(), sa.Metadata)
 		if errIndexRules != nil {
 			return errIndexRules
 		}
 		sm, errTS := openStream(s.root, streamSpec{
-			schema:     sa,
-			indexRules: iRules,
+			schema:            sa,
+			indexRules:        iRules,
+			retentionInterval: s.retentionInterval,
+			backfillWindow:    s.backfillWindow,
+			walSyncWrites:     s.walSyncWrites,
+			coldRoot:          s.coldRoot,
+			coldAge:           s.coldAge,
+			tieringInterval:   s.tieringInterval,
 		}, s.l)
 		if errTS != nil {
 			return errTS
@@ -113,6 +141,7 @@ func (s *service) PreRun() error {
 		s.l.Info().Str("id", id).Msg("initialize stream")
 	}
 	s.writeListener = setUpWriteCallback(s.l, s.schemaMap)
+	s.statsListener = setUpStatsCallback(s.l, s.schemaMap)
 	return err
 }
 
@@ -170,6 +199,10 @@ func (s *service) Serve() error {
 	if errWrite != nil {
 		return errWrite
 	}
+	errStats := s.pipeline.Subscribe(data.TopicStreamStats, s.statsListener)
+	if errStats != nil {
+		return errStats
+	}
 	s.stopCh = make(chan struct{})
 	<-s.stopCh
 	return nil
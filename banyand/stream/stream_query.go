@@ -28,8 +28,10 @@ import (
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
+	"github.com/apache/skywalking-banyandb/banyand/kv"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/pkg/partition"
+	pbv1 "github.com/apache/skywalking-banyandb/pkg/pb/v1"
 )
 
 var (
@@ -46,6 +48,7 @@ type Stream interface {
 	Shards(entity tsdb.Entity) ([]tsdb.Shard, error)
 	Shard(id common.ShardID) (tsdb.Shard, error)
 	ParseTagFamily(family string, item tsdb.Item) (*modelv1.TagFamily, error)
+	ProjectTagFamily(family string, tagNames []string, item tsdb.Item) (*modelv1.TagFamily, error)
 	ParseElementID(item tsdb.Item) (string, error)
 }
 
@@ -76,22 +79,12 @@ func (s *stream) Shard(id common.ShardID) (tsdb.Shard, error) {
 }
 
 func (s *stream) ParseTagFamily(family string, item tsdb.Item) (*modelv1.TagFamily, error) {
-	familyRawBytes, err := item.Family(family)
-	if err != nil {
-		return nil, err
-	}
-	tagFamily := &modelv1.TagFamilyForWrite{}
-	err = proto.Unmarshal(familyRawBytes, tagFamily)
+	tagFamily, err := s.readTagFamily(family, item)
 	if err != nil {
 		return nil, err
 	}
 	tags := make([]*modelv1.Tag, len(tagFamily.GetTags()))
-	var tagSpec []*databasev1.TagSpec
-	for _, tf := range s.schema.GetTagFamilies() {
-		if tf.GetName() == family {
-			tagSpec = tf.GetTags()
-		}
-	}
+	tagSpec := s.tagSpec(family)
 	if tagSpec == nil {
 		return nil, ErrTagFamilyNotExist
 	}
@@ -109,6 +102,113 @@ func (s *stream) ParseTagFamily(family string, item tsdb.Item) (*modelv1.TagFami
 	}, err
 }
 
+// ProjectTagFamily reads the tags in the order of the names, the columnar layout reads the requested tags only
+func (s *stream) ProjectTagFamily(family string, tagNames []string, item tsdb.Item) (*modelv1.TagFamily, error) {
+	tags := make([]*modelv1.Tag, len(tagNames))
+	if !s.columnar() {
+		parsed, err := s.ParseTagFamily(family, item)
+		if err != nil {
+			return nil, err
+		}
+		for i, name := range tagNames {
+			tags[i] = &modelv1.Tag{
+				Key:   name,
+				Value: pbv1.NullTagValue,
+			}
+			for _, tag := range parsed.GetTags() {
+				if tag.GetKey() == name {
+					tags[i] = tag
+					break
+				}
+			}
+		}
+		return &modelv1.TagFamily{
+			Name: family,
+			Tags: tags,
+		}, nil
+	}
+	if s.tagSpec(family) == nil {
+		return nil, ErrTagFamilyNotExist
+	}
+	for i, name := range tagNames {
+		value, err := s.readTag(family, name, item)
+		if err != nil {
+			return nil, err
+		}
+		tags[i] = &modelv1.Tag{
+			Key:   name,
+			Value: value,
+		}
+	}
+	return &modelv1.TagFamily{
+		Name: family,
+		Tags: tags,
+	}, nil
+}
+
+// readTagFamily reads the tag family as it's written, the absent tags are null in the columnar layout
+func (s *stream) readTagFamily(family string, item tsdb.Item) (*modelv1.TagFamilyForWrite, error) {
+	tagFamily := &modelv1.TagFamilyForWrite{}
+	if !s.columnar() {
+		familyRawBytes, err := item.Family(family)
+		if err != nil {
+			return nil, err
+		}
+		if err = proto.Unmarshal(familyRawBytes, tagFamily); err != nil {
+			return nil, err
+		}
+		return tagFamily, nil
+	}
+	tagSpec := s.tagSpec(family)
+	if tagSpec == nil {
+		return nil, ErrTagFamilyNotExist
+	}
+	tagFamily.Tags = make([]*modelv1.TagValue, len(tagSpec))
+	for i, spec := range tagSpec {
+		value, err := s.readTag(family, spec.GetName(), item)
+		if err != nil {
+			return nil, err
+		}
+		tagFamily.Tags[i] = value
+	}
+	return tagFamily, nil
+}
+
+func (s *stream) readTag(family, tag string, item tsdb.Item) (*modelv1.TagValue, error) {
+	rawBytes, err := item.Family(string(tagIdentity(family, tag)))
+	if errors.Is(err, kv.ErrKeyNotFound) {
+		return pbv1.NullTagValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the absent tag isn't written, which might be read as an empty value
+	if len(rawBytes) < 1 {
+		return pbv1.NullTagValue, nil
+	}
+	value := &modelv1.TagValue{}
+	if err = proto.Unmarshal(rawBytes, value); err != nil {
+		return nil, err
+	}
+	if value.GetValue() == nil {
+		return pbv1.NullTagValue, nil
+	}
+	return value, nil
+}
+
+func (s *stream) tagSpec(family string) []*databasev1.TagSpec {
+	for _, tf := range s.schema.GetTagFamilies() {
+		if tf.GetName() == family {
+			return tf.GetTags()
+		}
+	}
+	return nil
+}
+
+func (s *stream) columnar() bool {
+	return s.schema.GetOpts().GetTagStorageLayout() == databasev1.TagStorageLayout_TAG_STORAGE_LAYOUT_COLUMNAR
+}
+
 func (s *stream) ParseElementID(item tsdb.Item) (string, error) {
 	rawBytes, err := item.Val()
 	if err != nil {
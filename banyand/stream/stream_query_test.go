@@ -536,7 +536,7 @@ func Test_Stream_Global_Index(t *testing.T) {
 	tester := assert.New(t)
 	s, deferFunc := setup(t)
 	defer deferFunc()
-	_ = setupQueryData(t, "global_index.json", s)
+	baseTime := setupQueryData(t, "global_index.json", s)
 	tests := []struct {
 		name                string
 		traceID             string
@@ -571,7 +571,7 @@ func Test_Stream_Global_Index(t *testing.T) {
 							IndexRuleID: 10,
 						},
 						Term: []byte(tt.traceID),
-					})
+					}, tsdb.NewTimeRangeDuration(baseTime, 1*time.Hour))
 					if err != nil {
 						return errors.WithStack(err)
 					}
@@ -627,6 +627,57 @@ func Test_Stream_Global_Index(t *testing.T) {
 	}
 }
 
+func Test_Stream_Columnar_Tags(t *testing.T) {
+	tester := assert.New(t)
+	s, deferFunc := setup(t)
+	defer deferFunc()
+	s.schema.Opts.TagStorageLayout = databasev1.TagStorageLayout_TAG_STORAGE_LAYOUT_COLUMNAR
+	baseTime := setupQueryData(t, "multiple_shards.json", s)
+	opts := queryOpts{
+		entity:    tsdb.Entity{tsdb.AnyEntry, tsdb.AnyEntry, tsdb.AnyEntry},
+		timeRange: tsdb.NewTimeRangeDuration(baseTime, 1*time.Hour),
+	}
+	got, err := queryData(tester, s, opts)
+	tester.NoError(err)
+	var traceIDs []string
+	for _, g := range got {
+		traceIDs = append(traceIDs, g.elements...)
+	}
+	sort.Strings(traceIDs)
+	tester.Equal([]string{"1", "2", "3", "4", "5"}, traceIDs)
+
+	shards, err := s.Shards(opts.entity)
+	tester.NoError(err)
+	var projected int
+	for _, shard := range shards {
+		seriesList, errList := shard.Series().List(tsdb.NewPath(opts.entity))
+		tester.NoError(errList)
+		for _, series := range seriesList {
+			sp, errSpan := series.Span(opts.timeRange)
+			tester.NoError(errSpan)
+			seeker, errSeeker := sp.SeekerBuilder().Build()
+			tester.NoError(errSeeker)
+			iters, errSeek := seeker.Seek()
+			tester.NoError(errSeek)
+			for _, iter := range iters {
+				for iter.Next() {
+					tagFamily, errProject := s.ProjectTagFamily("searchable", []string{"duration", "trace_id", "mq.queue"}, iter.Val())
+					tester.NoError(errProject)
+					tester.Len(tagFamily.GetTags(), 3)
+					tester.Equal("duration", tagFamily.GetTags()[0].GetKey())
+					tester.NotZero(tagFamily.GetTags()[0].GetValue().GetInt().GetValue())
+					tester.Contains(traceIDs, tagFamily.GetTags()[1].GetValue().GetStr().GetValue())
+					tester.IsType(&modelv1.TagValue_Null{}, tagFamily.GetTags()[2].GetValue().GetValue(), "the absent tag is null")
+					projected++
+				}
+				_ = iter.Close()
+			}
+			_ = sp.Close()
+		}
+	}
+	tester.Equal(5, projected)
+}
+
 type queryOpts struct {
 	entity    tsdb.Entity
 	timeRange tsdb.TimeRange
@@ -18,10 +18,13 @@
 package stream
 
 import (
+	"bytes"
+
 	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/apache/skywalking-banyandb/api/common"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	streamv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/stream/v1"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb/index"
@@ -34,13 +37,16 @@ var (
 	ErrMalformedElement = errors.New("element is malformed")
 )
 
+// tagSeparator separates the family and the tag in the identity of a tag column
+const tagSeparator byte = 0
+
 func (s *stream) Write(value *streamv1.ElementValue) error {
 	entity, shardID, err := s.entityLocator.Locate(value.GetTagFamilies(), s.schema.GetOpts().GetShardNum())
 	if err != nil {
 		return err
 	}
 	waitCh := make(chan struct{})
-	err = s.write(shardID, tsdb.HashEntity(entity), value, func() {
+	err = s.write(shardID, entity, value, func() {
 		close(waitCh)
 	})
 	if err != nil {
@@ -51,7 +57,34 @@ func (s *stream) Write(value *streamv1.ElementValue) error {
 	return nil
 }
 
-func (s *stream) write(shardID common.ShardID, seriesHashKey []byte, value *streamv1.ElementValue, cb index.CallbackFn) error {
+// write records the value in the write-ahead log of the shard before applying it.
+// The entry is done once both the data and the indices are written. The value kept in the memory isn't logged.
+func (s *stream) write(shardID common.ShardID, entity tsdb.Entity, value *streamv1.ElementValue, cb index.CallbackFn) error {
+	if s.schema.GetOpts().GetInMemory() {
+		return s.apply(shardID, entity, value, nil, cb)
+	}
+	data, err := proto.Marshal(&streamv1.InternalWriteRequest{
+		ShardId: uint32(shardID),
+		Request: &streamv1.WriteRequest{
+			Metadata: s.schema.GetMetadata(),
+			Element:  value,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return s.wal.Write(shardID, data, func(source []byte, done func()) error {
+		return s.apply(shardID, entity, value, source, func() {
+			done()
+			if cb != nil {
+				cb()
+			}
+		})
+	})
+}
+
+// apply writes the value into the database, the source identifies the write-ahead log entry of the value
+func (s *stream) apply(shardID common.ShardID, entity tsdb.Entity, value *streamv1.ElementValue, source []byte, cb index.CallbackFn) error {
 	sm := s.schema
 	fLen := len(value.GetTagFamilies())
 	if fLen < 1 {
@@ -64,20 +97,24 @@ func (s *stream) write(shardID common.ShardID, seriesHashKey []byte, value *stre
 	if err != nil {
 		return err
 	}
-	series, err := shard.Series().GetByHashKey(seriesHashKey)
+	series, err := shard.Series().Get(entity)
 	if err != nil {
 		return err
 	}
 	t := value.GetTimestamp().AsTime()
-	wp, err := series.Span(tsdb.NewTimeRangeDuration(t, 0))
+	wp, err := series.Create(t)
 	if err != nil {
 		if wp != nil {
 			_ = wp.Close()
 		}
 		return err
 	}
+	columnar := sm.GetOpts().GetTagStorageLayout() == databasev1.TagStorageLayout_TAG_STORAGE_LAYOUT_COLUMNAR
 	writeFn := func() (tsdb.Writer, error) {
 		builder := wp.WriterBuilder().Time(t)
+		if source != nil {
+			builder.Source(source)
+		}
 		for fi, family := range value.GetTagFamilies() {
 			familySpec := sm.GetTagFamilies()[fi]
 			if len(family.GetTags()) > len(familySpec.GetTags()) {
@@ -92,6 +129,17 @@ func (s *stream) write(shardID common.ShardID, seriesHashKey []byte, value *stre
 				if tType != tagSpec.GetType() {
 					return nil, errors.Wrapf(ErrMalformedElement, "tag %s type is unexpected", tagSpec.GetName())
 				}
+				if !columnar {
+					continue
+				}
+				bb, errMarshal := proto.Marshal(tag)
+				if errMarshal != nil {
+					return nil, errMarshal
+				}
+				builder.Family(tagIdentity(familySpec.GetName(), tagSpec.GetName()), bb)
+			}
+			if columnar {
+				continue
 			}
 			bb, errMarshal := proto.Marshal(family)
 			if errMarshal != nil {
@@ -153,9 +201,26 @@ func (w *writeCallback) Rev(message bus.Message) (resp bus.Message) {
 	}
 	sm := writeEvent.GetRequest().GetMetadata()
 	id := formatStreamID(sm.GetName(), sm.GetGroup())
-	err := w.schemaMap[id].write(common.ShardID(writeEvent.GetShardId()), writeEvent.GetSeriesHash(), writeEvent.GetRequest().GetElement(), nil)
+	s := w.schemaMap[id]
+	value := writeEvent.GetRequest().GetElement()
+	// the entity is stored along with the series, so it's found from the tags instead of the hash key
+	entity, err := s.entityLocator.Find(value.GetTagFamilies())
+	if err != nil {
+		w.l.Error().Err(err).Msg("failed to find the entity")
+		return
+	}
+	err = s.write(common.ShardID(writeEvent.GetShardId()), entity, value, nil)
+	if errors.Is(err, tsdb.ErrDuplicatedItem) {
+		w.l.Warn().Err(err).Msg("reject the duplicated element")
+		return
+	}
 	if err != nil {
-		w.l.Debug().Err(err)
+		w.l.Error().Err(err).Msg("failed to write the element")
 	}
 	return
 }
+
+// tagIdentity is the identity of the column storing a tag in the columnar layout
+func tagIdentity(family, tag string) []byte {
+	return bytes.Join([][]byte{[]byte(family), []byte(tag)}, []byte{tagSeparator})
+}
@@ -0,0 +1,98 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"sync"
+
+	"go.uber.org/multierr"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+)
+
+// Batch collects the items written by the writers bound to it, see WriterBuilder.Batch.
+// The data and the indices of the items are committed once per underlying store instead of once per item.
+// The items, even the ones in different series, could share a batch. But their spans should not be closed
+// until the batch is committed.
+type Batch interface {
+	// Len returns the number of the collected items
+	Len() int
+	// Commit writes the collected items and resets the batch
+	Commit() error
+}
+
+type batchKey struct {
+	shardID common.ShardID
+	segID   uint16
+	blockID uint16
+}
+
+var _ Batch = (*batch)(nil)
+
+type batch struct {
+	sync.Mutex
+	blocks map[batchKey]*blockBatch
+	items  int
+}
+
+func NewBatch() Batch {
+	return &batch{
+		blocks: make(map[batchKey]*blockBatch),
+	}
+}
+
+func (b *batch) Len() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.items
+}
+
+func (b *batch) Commit() error {
+	b.Lock()
+	blocks := b.blocks
+	b.blocks = make(map[batchKey]*blockBatch)
+	b.items = 0
+	b.Unlock()
+	var err error
+	for _, bb := range blocks {
+		err = multierr.Append(err, bb.commit())
+	}
+	return err
+}
+
+func (b *batch) block(itemID *GlobalItemID, block blockDelegate) *blockBatch {
+	b.Lock()
+	defer b.Unlock()
+	key := batchKey{
+		shardID: itemID.ShardID,
+		segID:   itemID.segID,
+		blockID: itemID.blockID,
+	}
+	bb, ok := b.blocks[key]
+	if !ok {
+		bb = block.newBatch()
+		b.blocks[key] = bb
+	}
+	return bb
+}
+
+func (b *batch) add() {
+	b.Lock()
+	defer b.Unlock()
+	b.items++
+}
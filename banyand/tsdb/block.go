@@ -18,16 +18,21 @@
 package tsdb
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto/z"
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 
 	"github.com/apache/skywalking-banyandb/api/common"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	"github.com/apache/skywalking-banyandb/banyand/kv"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/index/inverted"
 	"github.com/apache/skywalking-banyandb/pkg/index/lsm"
@@ -43,17 +48,30 @@ type block struct {
 	primaryIndex  index.Store
 	invertedIndex index.Store
 	lsmIndex      index.Store
+	tombstone     *tombstone
+	barrier       *writeBarrier
 	closableLst   []io.Closer
-	endTime       time.Time
-	startTime     time.Time
-	segID         uint16
-	blockID       uint16
+	lock          sync.RWMutex
+	// writeLock serializes the checking of duplicates and the writing
+	writeLock       sync.Mutex
+	duplicatePolicy databasev1.DuplicatePolicy
+	endTime         time.Time
+	startTime       time.Time
+	segID           uint16
+	blockID         uint16
+	inMemory        bool
 }
 
 type blockOpts struct {
-	segID   uint16
-	blockID uint16
-	path    string
+	segID     uint16
+	blockID   uint16
+	path      string
+	startTime time.Time
+	tombstone *tombstone
+	// barrier is shared by the blocks in a segment, see segment.enterWrite
+	barrier *writeBarrier
+	// readOnly opens the stores in the read-only mode
+	readOnly bool
 }
 
 func newBlock(ctx context.Context, opts blockOpts) (b *block, err error) {
@@ -62,8 +80,11 @@ func newBlock(ctx context.Context, opts blockOpts) (b *block, err error) {
 		blockID:   opts.blockID,
 		path:      opts.path,
 		ref:       z.NewCloser(1),
-		startTime: time.Now(),
+		startTime: opts.startTime,
+		tombstone: opts.tombstone,
+		barrier:   opts.barrier,
 	}
+	b.inMemory, _ = ctx.Value(inMemoryKey).(bool)
 	parentLogger := ctx.Value(logger.ContextKey)
 	if parentLogger != nil {
 		if pl, ok := parentLogger.(*logger.Logger); ok {
@@ -75,17 +96,30 @@ func newBlock(ctx context.Context, opts blockOpts) (b *block, err error) {
 		return nil, errors.Wrap(ErrEncodingMethodAbsent, "failed to create a block")
 	}
 	encodingMethod := encodingMethodObject.(EncodingMethod)
-	if b.store, err = kv.OpenTimeSeriesStore(
-		0,
-		b.path+"/store",
+	if !opts.readOnly {
+		if err = removeManifest(b.path); err != nil {
+			return nil, err
+		}
+	}
+	b.duplicatePolicy, _ = ctx.Value(duplicatePolicyKey).(databasev1.DuplicatePolicy)
+	storeOpts := []kv.TimeSeriesOptions{
 		kv.TSSWithEncoding(encodingMethod.EncoderPool, encodingMethod.DecoderPool),
 		kv.TSSWithLogger(b.l),
-	); err != nil {
+	}
+	if opts.readOnly {
+		storeOpts = append(storeOpts, kv.TSSWithReadOnly())
+	}
+	if b.inMemory {
+		storeOpts = append(storeOpts, kv.TSSWithInMemory())
+	}
+	if b.store, err = kv.OpenTimeSeriesStore(0, b.path+"/store", storeOpts...); err != nil {
 		return nil, err
 	}
 	if b.primaryIndex, err = lsm.NewStore(lsm.StoreOpts{
-		Path:   b.path + "/primary",
-		Logger: b.l,
+		Path:     b.path + "/primary",
+		Logger:   b.l,
+		ReadOnly: opts.readOnly,
+		InMemory: b.inMemory,
 	}); err != nil {
 		return nil, err
 	}
@@ -95,14 +129,18 @@ func newBlock(ctx context.Context, opts blockOpts) (b *block, err error) {
 		return b, nil
 	}
 	if b.invertedIndex, err = inverted.NewStore(inverted.StoreOpts{
-		Path:   b.path + "/inverted",
-		Logger: b.l,
+		Path:     b.path + "/inverted",
+		Logger:   b.l,
+		ReadOnly: opts.readOnly,
+		InMemory: b.inMemory,
 	}); err != nil {
 		return nil, err
 	}
 	if b.lsmIndex, err = lsm.NewStore(lsm.StoreOpts{
-		Path:   b.path + "/lsm",
-		Logger: b.l,
+		Path:     b.path + "/lsm",
+		Logger:   b.l,
+		ReadOnly: opts.readOnly,
+		InMemory: b.inMemory,
 	}); err != nil {
 		return nil, err
 	}
@@ -125,21 +163,174 @@ func (b *block) incRef() {
 	b.ref.AddRunning(1)
 }
 
+// setEndTime seals the block, whose stores are compacted since no more data is coming
+func (b *block) setEndTime(endTime time.Time) {
+	b.lock.Lock()
+	sealing := b.endTime.IsZero() && !endTime.IsZero()
+	b.endTime = endTime
+	b.lock.Unlock()
+	if sealing {
+		b.requestCompaction()
+	}
+}
+
+func (b *block) requestCompaction() {
+	b.store.RequestCompaction()
+	for _, idx := range []index.Store{b.primaryIndex, b.invertedIndex, b.lsmIndex} {
+		if idx != nil {
+			idx.RequestCompaction()
+		}
+	}
+}
+
+// checkItems checks whether the block has items of the series, and whether any of them survives the tombstone
+func (b *block) checkItems(seriesID common.SeriesID) (found, alive bool, err error) {
+	iter, err := b.primaryIndex.Iterator(index.FieldKey{SeriesID: seriesID}, index.RangeOpts{}, modelv1.Sort_SORT_ASC)
+	if err != nil || iter == nil {
+		return false, false, err
+	}
+	defer func() {
+		err = multierr.Append(err, iter.Close())
+	}()
+	ranges := b.tombstone.get(seriesID)
+	for iter.Next() {
+		found = true
+		if !deleted(ranges, uint64(convert.BytesToInt64(iter.Val().Term))) {
+			return true, true, nil
+		}
+	}
+	return found, false, nil
+}
+
+// latestItemTime returns the time of the latest item of the series in the block
+func (b *block) latestItemTime(seriesID common.SeriesID) (latest time.Time, found bool, err error) {
+	b.incRef()
+	defer b.dscRef()
+	iter, err := b.primaryIndex.Iterator(index.FieldKey{SeriesID: seriesID}, index.RangeOpts{}, modelv1.Sort_SORT_DESC)
+	if err != nil || iter == nil {
+		return latest, false, err
+	}
+	defer func() {
+		err = multierr.Append(err, iter.Close())
+	}()
+	if !iter.Next() {
+		return latest, false, nil
+	}
+	return time.Unix(0, convert.BytesToInt64(iter.Val().Term)), true, nil
+}
+
+// dropSeries drops the keys of the series from the stores, then the compaction reclaims their space
+func (b *block) dropSeries(seriesID common.SeriesID) error {
+	err := b.store.DeletePrefix(seriesID.Marshal())
+	for _, idx := range []index.Store{b.primaryIndex, b.invertedIndex, b.lsmIndex} {
+		if idx != nil {
+			err = multierr.Append(err, idx.DeleteSeries(seriesID))
+		}
+	}
+	if err != nil {
+		return errors.WithMessagef(err, "failed to drop the series %d from the block %s", seriesID, b.path)
+	}
+	b.requestCompaction()
+	return nil
+}
+
+func (b *block) contains(ts time.Time) bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	greaterAndEqualStart := b.startTime.Equal(ts) || b.startTime.Before(ts)
+	if b.endTime.IsZero() {
+		return greaterAndEqualStart
+	}
+	return greaterAndEqualStart && b.endTime.After(ts)
+}
+
+func (b *block) overlapping(timeRange TimeRange) bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return timeRange.overlapping(b.startTime, b.endTime)
+}
+
+func (b *block) snapshot(dir string) error {
+	b.incRef()
+	defer b.dscRef()
+	err := multierr.Combine(
+		b.store.Snapshot(dir+"/store"),
+		b.primaryIndex.Snapshot(dir+"/primary"),
+	)
+	if b.invertedIndex != nil {
+		err = multierr.Append(err, b.invertedIndex.Snapshot(dir+"/inverted"))
+	}
+	if b.lsmIndex != nil {
+		err = multierr.Append(err, b.lsmIndex.Snapshot(dir+"/lsm"))
+	}
+	return err
+}
+
+// stats counts the items by the primary index, each item has an entry in it
+func (b *block) stats() BlockStats {
+	b.incRef()
+	defer b.dscRef()
+	b.lock.RLock()
+	result := BlockStats{
+		ID:        b.blockID,
+		StartTime: b.startTime,
+		EndTime:   b.endTime,
+	}
+	b.lock.RUnlock()
+	storeStats := b.store.Stats()
+	primaryStats := b.primaryIndex.Stats()
+	result.DiskSize = storeStats.DiskSize + primaryStats.DiskSize
+	result.ItemCount = primaryStats.EntryCount
+	for _, idx := range []index.Store{b.invertedIndex, b.lsmIndex} {
+		if idx == nil {
+			continue
+		}
+		idxStats := idx.Stats()
+		result.DiskSize += idxStats.DiskSize
+		result.IndexTermCount += idxStats.KeyCount
+	}
+	return result
+}
+
+// close writes the manifest of a sealed block once the files stop changing.
+// There is nothing to verify in a block kept in the memory.
 func (b *block) close() {
 	b.dscRef()
 	b.ref.SignalAndWait()
 	for _, closer := range b.closableLst {
 		_ = closer.Close()
 	}
+	b.lock.RLock()
+	sealed := !b.endTime.IsZero()
+	b.lock.RUnlock()
+	if !sealed || b.inMemory {
+		return
+	}
+	if err := writeManifest(b.path, blockDirs...); err != nil {
+		b.l.Warn().Err(err).Str("path", b.path).Msg("failed to write the manifest")
+	}
 }
 
-type blockDelegate interface {
-	io.Closer
-	contains(ts time.Time) bool
+type blockWriter interface {
 	write(key []byte, val []byte, ts time.Time) error
 	writePrimaryIndex(field index.Field, id common.ItemID) error
 	writeLSMIndex(field index.Field, id common.ItemID) error
 	writeInvertedIndex(field index.Field, id common.ItemID) error
+}
+
+type blockDelegate interface {
+	io.Closer
+	blockWriter
+	contains(ts time.Time) bool
+	// enterWrite lets a write into the stores, the returned function should be invoked once it's done.
+	// The writes to a read-only segment are rejected.
+	enterWrite() (func(), error)
+	newBatch() *blockBatch
+	// allocateItemID returns the item id of the item written at ts according to the duplicate policy,
+	// or the id of the item written from the same source. The returned function should be invoked once the item is written.
+	allocateItemID(seriesID common.SeriesID, ts time.Time, source []byte) (common.ItemID, func(), error)
+	delete(seriesID common.SeriesID, timeRange TimeRange) error
+	deletedRanges(seriesID common.SeriesID) []TimeRange
 	dataReader() kv.TimeSeriesReader
 	lsmIndexReader() index.Searcher
 	invertedIndexReader() index.Searcher
@@ -200,15 +391,170 @@ func (d *bDelegate) writeInvertedIndex(field index.Field, id common.ItemID) erro
 	return d.delegate.invertedIndex.Write(field, id)
 }
 
-func (d *bDelegate) contains(ts time.Time) bool {
-	greaterAndEqualStart := d.delegate.startTime.Equal(ts) || d.delegate.startTime.Before(ts)
-	if d.delegate.endTime.IsZero() {
-		return greaterAndEqualStart
+func (d *bDelegate) enterWrite() (func(), error) {
+	exit, err := d.delegate.barrier.enter()
+	if err != nil {
+		return nil, errors.WithMessagef(err, "block: %s", d.delegate.path)
+	}
+	return exit, nil
+}
+
+func (d *bDelegate) newBatch() *blockBatch {
+	bb := &blockBatch{
+		enterWrite:   d.enterWrite,
+		data:         d.delegate.store.NewBatch(),
+		primaryIndex: d.delegate.primaryIndex.NewBatch(),
+	}
+	if d.delegate.invertedIndex != nil {
+		bb.invertedIndex = d.delegate.invertedIndex.NewBatch()
+	}
+	if d.delegate.lsmIndex != nil {
+		bb.lsmIndex = d.delegate.lsmIndex.NewBatch()
+	}
+	return bb
+}
+
+func (d *bDelegate) allocateItemID(seriesID common.SeriesID, ts time.Time, source []byte) (common.ItemID, func(), error) {
+	b := d.delegate
+	switch b.duplicatePolicy {
+	case databasev1.DuplicatePolicy_DUPLICATE_POLICY_REJECT, databasev1.DuplicatePolicy_DUPLICATE_POLICY_KEEP:
+	default:
+		// the item written from the same source is overwritten by itself
+		id, err := newItemID(ts, b.startTime, 0)
+		return id, func() {}, err
+	}
+	b.writeLock.Lock()
+	// the primary index refers to all items written at ts, including the ones with a sequence
+	list, err := b.primaryIndex.MatchTerms(index.Field{
+		Key: index.FieldKey{
+			SeriesID: seriesID,
+		},
+		Term: convert.Int64ToBytes(ts.UnixNano()),
+	})
+	if err != nil {
+		b.writeLock.Unlock()
+		return 0, nil, err
+	}
+	if source != nil {
+		for _, id := range list.ToSlice() {
+			_, seq := parseItemID(id, b.startTime)
+			written, errGet := b.store.Get(dataBucket{
+				seriesID: seriesID,
+				family:   sourceFamily,
+				seq:      seq,
+			}.marshal(), uint64(ts.UnixNano()))
+			if errGet == nil && bytes.Equal(written, source) {
+				return id, b.writeLock.Unlock, nil
+			}
+		}
 	}
-	return greaterAndEqualStart && d.delegate.endTime.After(ts)
+	if list.Len() > 0 && b.duplicatePolicy == databasev1.DuplicatePolicy_DUPLICATE_POLICY_REJECT {
+		b.writeLock.Unlock()
+		return 0, nil, errors.Wrapf(ErrDuplicatedItem, "series: %d, time: %s", seriesID, ts)
+	}
+	// the items at ts take the sequences from 0 on
+	id, err := newItemID(ts, b.startTime, list.Len())
+	if err != nil {
+		b.writeLock.Unlock()
+		return 0, nil, err
+	}
+	return id, b.writeLock.Unlock, nil
+}
+
+// delete records the range in the tombstone. If none of the items of the series in the block survives,
+// the series is dropped from the stores. The writes are blocked to keep new items from slipping through the check.
+func (d *bDelegate) delete(seriesID common.SeriesID, timeRange TimeRange) error {
+	b := d.delegate
+	b.barrier.Lock()
+	defer b.barrier.Unlock()
+	if b.barrier.readOnly {
+		return errors.WithMessagef(ErrSegmentReadOnly, "block: %s", b.path)
+	}
+	if err := b.tombstone.add(seriesID, timeRange); err != nil {
+		return err
+	}
+	found, alive, err := b.checkItems(seriesID)
+	if err != nil || !found || alive {
+		return err
+	}
+	return b.dropSeries(seriesID)
+}
+
+func (d *bDelegate) deletedRanges(seriesID common.SeriesID) []TimeRange {
+	return d.delegate.tombstone.get(seriesID)
+}
+
+func (d *bDelegate) contains(ts time.Time) bool {
+	return d.delegate.contains(ts)
 }
 
 func (d *bDelegate) Close() error {
 	d.delegate.dscRef()
 	return nil
 }
+
+var _ blockWriter = (*blockBatch)(nil)
+
+// blockBatch collects the data and the indices of a block, which are committed once per store
+type blockBatch struct {
+	sync.Mutex
+	enterWrite    func() (func(), error)
+	data          kv.Batch
+	primaryIndex  index.Batch
+	invertedIndex index.Batch
+	lsmIndex      index.Batch
+}
+
+func (bb *blockBatch) write(key []byte, val []byte, ts time.Time) error {
+	bb.Lock()
+	defer bb.Unlock()
+	bb.data.Put(key, val, uint64(ts.UnixNano()))
+	return nil
+}
+
+func (bb *blockBatch) writePrimaryIndex(field index.Field, id common.ItemID) error {
+	bb.Lock()
+	defer bb.Unlock()
+	return bb.primaryIndex.Write(field, id)
+}
+
+func (bb *blockBatch) writeLSMIndex(field index.Field, id common.ItemID) error {
+	if bb.lsmIndex == nil {
+		return nil
+	}
+	bb.Lock()
+	defer bb.Unlock()
+	return bb.lsmIndex.Write(field, id)
+}
+
+func (bb *blockBatch) writeInvertedIndex(field index.Field, id common.ItemID) error {
+	if bb.invertedIndex == nil {
+		return nil
+	}
+	bb.Lock()
+	defer bb.Unlock()
+	return bb.invertedIndex.Write(field, id)
+}
+
+// commit writes the data ahead of the indices, which makes the indexed items always readable
+func (bb *blockBatch) commit() error {
+	// enters the barrier ahead of locking, the writers do the same
+	exit, err := bb.enterWrite()
+	if err != nil {
+		return err
+	}
+	defer exit()
+	bb.Lock()
+	defer bb.Unlock()
+	if err = bb.data.Commit(); err != nil {
+		return err
+	}
+	err = bb.primaryIndex.Commit()
+	if bb.invertedIndex != nil {
+		err = multierr.Append(err, bb.invertedIndex.Commit())
+	}
+	if bb.lsmIndex != nil {
+		err = multierr.Append(err, bb.lsmIndex.Commit())
+	}
+	return err
+}
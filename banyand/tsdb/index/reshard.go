@@ -0,0 +1,71 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package index
+
+import (
+	"time"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/banyand/tsdb"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/partition"
+)
+
+type ReshardOptions struct {
+	ShardNum   uint32
+	Families   []*databasev1.TagFamilySpec
+	IndexRules []*databasev1.IndexRule
+	// CopiedFamilies are the identities of the families to copy, see tsdb.ReshardOpts
+	CopiedFamilies [][]byte
+	// ReadTagFamily reads the tag family of the copied item as it's written
+	ReadTagFamily func(family string, item tsdb.Item) (*modelv1.TagFamilyForWrite, error)
+}
+
+// NewReshardOpts copies the families and rebuilds the indices from the tag families of each copied item
+func NewReshardOpts(l *logger.Logger, options ReshardOptions) tsdb.ReshardOpts {
+	shardNum := options.ShardNum
+	tagFamilies := options.Families
+	locators := partition.ParseIndexRuleLocators(tagFamilies, options.IndexRules)
+	return tsdb.ReshardOpts{
+		Families: options.CopiedFamilies,
+		Locate: func(entity tsdb.Entity) (common.ShardID, error) {
+			id, err := partition.ShardID(entity.Marshal(), shardNum)
+			return common.ShardID(id), err
+		},
+		OnWrite: func(db tsdb.Database, item tsdb.Item, writer tsdb.Writer) error {
+			value := Value{
+				TagFamilies: make([]*modelv1.TagFamilyForWrite, len(tagFamilies)),
+				Timestamp:   time.Unix(0, int64(item.Time())),
+			}
+			for i, f := range tagFamilies {
+				tagFamily, err := options.ReadTagFamily(f.GetName(), item)
+				if err != nil {
+					tagFamily = &modelv1.TagFamilyForWrite{}
+				}
+				value.TagFamilies[i] = tagFamily
+			}
+			// the indices are generated as the same as writing, whose failures don't break the data
+			if err := WriteIndices(db, shardNum, locators, writer, value); err != nil {
+				l.Warn().Err(err).Uint64("series_id", uint64(writer.ItemID().SeriesID)).Msg("failed to rebuild indices")
+			}
+			return nil
+		},
+	}
+}
@@ -103,16 +103,7 @@ func (s *Writer) bootIndexGenerator() {
 			if !more {
 				return
 			}
-			var err error
-			for _, ruleIndex := range s.indexRuleIndex {
-				rule := ruleIndex.Rule
-				switch rule.GetLocation() {
-				case databasev1.IndexRule_LOCATION_SERIES:
-					err = multierr.Append(err, writeLocalIndex(m.LocalWriter, ruleIndex, m.Value))
-				case databasev1.IndexRule_LOCATION_GLOBAL:
-					err = multierr.Append(err, s.writeGlobalIndex(ruleIndex, m.LocalWriter.ItemID(), m.Value))
-				}
-			}
+			err := WriteIndices(s.db, s.shardNum, s.indexRuleIndex, m.LocalWriter, m.Value)
 			err = multierr.Append(err, m.BlockCloser.Close())
 			if err != nil {
 				s.l.Error().Err(err).Msg("encounter some errors when generating indices")
@@ -124,17 +115,31 @@ func (s *Writer) bootIndexGenerator() {
 	}()
 }
 
+// WriteIndices generates the local and global indices of the value synchronously
+func WriteIndices(db tsdb.Database, shardNum uint32, locators []*partition.IndexRuleLocator, localWriter tsdb.Writer, value Value) (err error) {
+	for _, ruleIndex := range locators {
+		rule := ruleIndex.Rule
+		switch rule.GetLocation() {
+		case databasev1.IndexRule_LOCATION_SERIES:
+			err = multierr.Append(err, writeLocalIndex(localWriter, ruleIndex, value))
+		case databasev1.IndexRule_LOCATION_GLOBAL:
+			err = multierr.Append(err, writeGlobalIndex(db, shardNum, ruleIndex, localWriter.ItemID(), value))
+		}
+	}
+	return err
+}
+
 //TODO: should listen to pipeline in a distributed cluster
-func (s *Writer) writeGlobalIndex(ruleIndex *partition.IndexRuleLocator, ref tsdb.GlobalItemID, value Value) error {
+func writeGlobalIndex(db tsdb.Database, shardNum uint32, ruleIndex *partition.IndexRuleLocator, ref tsdb.GlobalItemID, value Value) error {
 	val, _, err := getIndexValue(ruleIndex, value)
 	if err != nil {
 		return err
 	}
-	indexShardID, err := partition.ShardID(val, s.shardNum)
+	indexShardID, err := partition.ShardID(val, shardNum)
 	if err != nil {
 		return err
 	}
-	shard, err := s.db.Shard(common.ShardID(indexShardID))
+	shard, err := db.Shard(common.ShardID(indexShardID))
 	if err != nil {
 		return err
 	}
@@ -30,7 +30,9 @@ import (
 
 type IndexDatabase interface {
 	WriterBuilder() IndexWriterBuilder
-	Seek(field index.Field) ([]GlobalItemID, error)
+	// Seek looks up the global indices of the segments overlapping the time range,
+	// only the items in the time range are returned
+	Seek(field index.Field, timeRange TimeRange) ([]GlobalItemID, error)
 }
 
 type IndexWriter interface {
@@ -51,45 +53,63 @@ var _ IndexDatabase = (*indexDB)(nil)
 
 type indexDB struct {
 	shardID common.ShardID
-	lst     []*segment
+	segCtrl *segmentController
 }
 
-func (i *indexDB) Seek(field index.Field) ([]GlobalItemID, error) {
+func (i *indexDB) Seek(field index.Field, timeRange TimeRange) ([]GlobalItemID, error) {
 	result := make([]GlobalItemID, 0)
 	f, err := field.MarshalStraight()
 	if err != nil {
 		return nil, err
 	}
-	err = i.lst[0].globalIndex.GetAll(f, func(rawBytes []byte) error {
-		id := &GlobalItemID{}
-		errUnMarshal := id.UnMarshal(rawBytes)
-		if errUnMarshal != nil {
-			return errUnMarshal
+	for _, seg := range i.segCtrl.segments() {
+		if !seg.overlapping(timeRange) {
+			continue
+		}
+		err = seg.globalIndex.GetAll(f, func(rawBytes []byte) error {
+			id := &GlobalItemID{}
+			errUnMarshal := id.UnMarshal(rawBytes)
+			if errUnMarshal != nil {
+				return errUnMarshal
+			}
+			// the item is gone along with its segment or block
+			itemSeg := i.segCtrl.get(id.segID)
+			if itemSeg == nil {
+				return nil
+			}
+			itemBlock := itemSeg.block(id.blockID)
+			if itemBlock == nil {
+				return nil
+			}
+			ts, _ := parseItemID(id.ID, itemBlock.startTime)
+			if !timeRange.contains(ts) || itemSeg.tombstone.deleted(id.SeriesID, ts) {
+				return nil
+			}
+			result = append(result, *id)
+			return nil
+		})
+		if err != nil && !errors.Is(err, kv.ErrKeyNotFound) {
+			return nil, err
 		}
-		result = append(result, *id)
-		return nil
-	})
-	if err == kv.ErrKeyNotFound {
-		return result, nil
 	}
-	return result, err
+	return result, nil
 }
 
 func (i *indexDB) WriterBuilder() IndexWriterBuilder {
-	return newIndexWriterBuilder(i.lst)
+	return newIndexWriterBuilder(i.segCtrl)
 }
 
-func newIndexDatabase(_ context.Context, id common.ShardID, lst []*segment) (IndexDatabase, error) {
+func newIndexDatabase(_ context.Context, id common.ShardID, segCtrl *segmentController) (IndexDatabase, error) {
 	return &indexDB{
 		shardID: id,
-		lst:     lst,
+		segCtrl: segCtrl,
 	}, nil
 }
 
 var _ IndexWriterBuilder = (*indexWriterBuilder)(nil)
 
 type indexWriterBuilder struct {
-	segments     []*segment
+	segCtrl      *segmentController
 	ts           time.Time
 	seg          *segment
 	globalItemID *GlobalItemID
@@ -97,7 +117,7 @@ type indexWriterBuilder struct {
 
 func (i *indexWriterBuilder) Time(ts time.Time) IndexWriterBuilder {
 	i.ts = ts
-	for _, s := range i.segments {
+	for _, s := range i.segCtrl.segments() {
 		if s.contains(ts) {
 			i.seg = s
 			break
@@ -125,9 +145,9 @@ func (i *indexWriterBuilder) Build() (IndexWriter, error) {
 	}, nil
 }
 
-func newIndexWriterBuilder(segments []*segment) IndexWriterBuilder {
+func newIndexWriterBuilder(segCtrl *segmentController) IndexWriterBuilder {
 	return &indexWriterBuilder{
-		segments: segments,
+		segCtrl: segCtrl,
 	}
 }
 
@@ -144,6 +164,11 @@ func (i *indexWriter) WriteLSMIndex(field index.Field) error {
 	if err != nil {
 		return err
 	}
+	exit, err := i.seg.enterWrite()
+	if err != nil {
+		return err
+	}
+	defer exit()
 	return i.seg.globalIndex.PutWithVersion(key, i.itemID.Marshal(), uint64(i.ts.UnixNano()))
 }
 
@@ -152,5 +177,10 @@ func (i *indexWriter) WriteInvertedIndex(field index.Field) error {
 	if err != nil {
 		return err
 	}
+	exit, err := i.seg.enterWrite()
+	if err != nil {
+		return err
+	}
+	defer exit()
 	return i.seg.globalIndex.PutWithVersion(key, i.itemID.Marshal(), uint64(i.ts.UnixNano()))
 }
@@ -0,0 +1,165 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	manifestName = "manifest.json"
+	// lockName is the lock file of badger, which exists while the store is open
+	lockName = "LOCK"
+)
+
+var (
+	// blockDirs are the stores of a block
+	blockDirs = []string{"store", "primary", "inverted", "lsm"}
+	// segmentDirs are the segment-level stores, the blocks have their own manifests
+	segmentDirs = []string{"index", "tombstone"}
+)
+
+// manifest records the checksums of the files in a block or the segment-level stores of a segment.
+// It's written once a sealed block or segment is closed, and removed once it's opened to write,
+// because the files of an open store keep changing.
+type manifest struct {
+	Files map[string]fileDigest `json:"files"`
+}
+
+type fileDigest struct {
+	Size  int64  `json:"size"`
+	CRC32 uint32 `json:"crc32"`
+}
+
+// writeManifest computes the digests of the files in the dirs relative to root
+func writeManifest(root string, dirs ...string) error {
+	m, err := digestDirs(root, dirs...)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(root, manifestName)
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write the manifest %s", tmp)
+	}
+	return errors.Wrapf(os.Rename(tmp, path), "failed to write the manifest %s", path)
+}
+
+func removeManifest(root string) error {
+	if err := os.Remove(filepath.Join(root, manifestName)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove the manifest in %s", root)
+	}
+	return nil
+}
+
+func readManifest(root string) (*manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(root, manifestName))
+	if err != nil {
+		return nil, err
+	}
+	m := &manifest{}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrapf(err, "malformed manifest in %s", root)
+	}
+	return m, nil
+}
+
+// verifyManifest returns the problems of the files against the manifest in root, the manifest covers the dirs
+func verifyManifest(root string, m *manifest, dirs ...string) ([]string, error) {
+	actual, err := digestDirs(root, dirs...)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for name, expected := range m.Files {
+		d, ok := actual.Files[name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is missing", name))
+		case d.Size != expected.Size:
+			problems = append(problems, fmt.Sprintf("%s is %d bytes, expected %d bytes", name, d.Size, expected.Size))
+		case d.CRC32 != expected.CRC32:
+			problems = append(problems, fmt.Sprintf("%s has a mismatched checksum", name))
+		}
+	}
+	for name := range actual.Files {
+		if _, ok := m.Files[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s is unexpected", name))
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+func digestDirs(root string, dirs ...string) (*manifest, error) {
+	m := &manifest{Files: make(map[string]fileDigest)}
+	for _, dir := range dirs {
+		err := filepath.Walk(filepath.Join(root, dir), func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || info.Name() == lockName {
+				return nil
+			}
+			crc, err := fileCRC32(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			m.Files[filepath.ToSlash(rel)] = fileDigest{
+				Size:  info.Size(),
+				CRC32: crc,
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "failed to digest %s", dir)
+		}
+	}
+	return m, nil
+}
+
+func fileCRC32(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	h := crc32.NewIEEE()
+	if _, err = io.Copy(h, f); err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
@@ -0,0 +1,336 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+)
+
+const (
+	reshardingSuffix = ".resharding"
+	movingSuffix     = ".moving"
+)
+
+var ErrEntityAbsent = errors.New("the entity of the series is absent")
+
+type ReshardOpts struct {
+	// Families are the names of the families to copy, nil stands for the value written by WriterBuilder.Val
+	Families [][]byte
+	// Locate returns the shard of the entity in the resharded database
+	Locate func(entity Entity) (common.ShardID, error)
+	// OnWrite is invoked once an item is copied to rebuild its indices in the resharded database
+	OnWrite func(db Database, item Item, writer Writer) error
+}
+
+// Reshard rewrites the database in the location if its shard number is different from the one of opts.
+// All the shards in the location are moved, so the location should be owned by a single resource
+// instead of being shared with other ones.
+// The existing data is moved aside, copied to the new shards and removed in the end. An interrupted job is
+// resumed from the data moved aside when it's invoked again.
+func Reshard(ctx context.Context, opts DatabaseOpts, reshardOpts ReshardOpts) error {
+	l := logger.GetLogger("reshard")
+	if pl, ok := ctx.Value(logger.ContextKey).(*logger.Logger); ok {
+		l = pl.Named("reshard")
+	}
+	srcLocation := opts.Location + reshardingSuffix
+	if _, err := os.Stat(srcLocation); err == nil {
+		l.Warn().Str("path", srcLocation).Msg("resume the interrupted resharding")
+		if err = removeShards(opts.Location); err != nil {
+			return err
+		}
+	} else {
+		shardNum, errShardNum := shardNumInLocation(opts.Location)
+		if errShardNum != nil {
+			return errShardNum
+		}
+		_, errMoving := os.Stat(srcLocation + movingSuffix)
+		if errMoving != nil && (shardNum == 0 || shardNum == opts.ShardNum) {
+			return nil
+		}
+		if err = moveShards(opts.Location, srcLocation); err != nil {
+			return err
+		}
+	}
+	srcShardNum, err := shardNumInLocation(srcLocation)
+	if err != nil {
+		return err
+	}
+	srcOpts := opts
+	srcOpts.Location = srcLocation
+	srcOpts.ShardNum = srcShardNum
+	srcOpts.RetentionInterval = 0
+	srcOpts.BackfillWindow = 0
+	srcOpts.TieringInterval = 0
+	src, err := OpenDatabase(ctx, srcOpts)
+	if err != nil {
+		return err
+	}
+	dstOpts := opts
+	dstOpts.RetentionInterval = 0
+	dstOpts.BackfillWindow = 0
+	// the expired data is copied as well, and the retention removes it after the resharding
+	dstOpts.TTL = CalendarInterval{}
+	// the segments in the cold location are copied to the new shards, which are moved again by the tiering
+	dstOpts.ColdLocation = ""
+	dstOpts.TieringInterval = 0
+	dst, err := OpenDatabase(ctx, dstOpts)
+	if err != nil {
+		return multierr.Append(err, src.Close())
+	}
+	l.Info().Uint32("from", srcShardNum).Uint32("to", opts.ShardNum).Str("path", opts.Location).Msg("start resharding")
+	err = reshard(src, dst, reshardOpts)
+	err = multierr.Combine(err, src.Close(), dst.Close())
+	if err != nil {
+		return errors.WithMessagef(err, "failed to reshard %s", opts.Location)
+	}
+	if err = os.RemoveAll(srcLocation); err != nil {
+		return errors.Wrapf(err, "failed to remove %s", srcLocation)
+	}
+	if opts.ColdLocation != "" {
+		if err = removeShards(opts.ColdLocation); err != nil {
+			return err
+		}
+	}
+	l.Info().Uint32("from", srcShardNum).Uint32("to", opts.ShardNum).Str("path", opts.Location).Msg("resharded")
+	return nil
+}
+
+func reshard(src, dst Database, opts ReshardOpts) error {
+	records := make(map[common.ShardID][]seriesRecord, len(src.Shards()))
+	for _, s := range src.Shards() {
+		rr, err := s.Series().(*seriesDB).all()
+		if err != nil {
+			return err
+		}
+		// the series created without the entity can't be routed to the new shard
+		for _, r := range rr {
+			if r.entity == nil {
+				return errors.WithMessagef(ErrEntityAbsent, "shard: %d, series: %d", s.ID(), r.id)
+			}
+		}
+		records[s.ID()] = rr
+	}
+	for _, s := range src.Shards() {
+		for _, r := range records[s.ID()] {
+			shardID, err := opts.Locate(r.entity)
+			if err != nil {
+				return err
+			}
+			dstShard, err := dst.Shard(shardID)
+			if err != nil {
+				return err
+			}
+			dstSeries, err := dstShard.Series().Get(r.entity)
+			if err != nil {
+				return err
+			}
+			srcSeries, err := s.Series().GetByID(r.id)
+			if err != nil {
+				return err
+			}
+			if err = copySeries(dst, srcSeries, dstSeries, opts); err != nil {
+				return errors.WithMessagef(err, "failed to copy the series %d in the shard %d", r.id, s.ID())
+			}
+		}
+	}
+	return nil
+}
+
+func copySeries(dst Database, src, target Series, opts ReshardOpts) (err error) {
+	span, err := src.Span(NewTimeRange(time.Unix(0, 0), time.Unix(0, math.MaxInt64)))
+	if errors.Is(err, ErrEmptySeriesSpan) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, span.Close())
+	}()
+	seeker, err := span.SeekerBuilder().OrderByTime(modelv1.Sort_SORT_ASC).Build()
+	if err != nil {
+		return err
+	}
+	iters, err := seeker.Seek()
+	if err != nil {
+		return err
+	}
+	c := &copier{
+		dst:     dst,
+		target:  target,
+		opts:    opts,
+		batch:   NewBatch(),
+		pending: make(map[uint64]struct{}),
+	}
+	for _, iter := range iters {
+		for iter.Next() {
+			if err = c.copy(iter.Val()); err != nil {
+				break
+			}
+		}
+		err = multierr.Append(err, iter.Close())
+		if err != nil {
+			return multierr.Append(err, c.flush())
+		}
+	}
+	return c.flush()
+}
+
+const reshardBatchSize = 1000
+
+// copier writes the items to the target series in batches
+type copier struct {
+	dst    Database
+	target Series
+	opts   ReshardOpts
+	batch  Batch
+	spans  []SeriesSpan
+	// pending holds the timestamps of the items in the batch
+	pending map[uint64]struct{}
+}
+
+func (c *copier) copy(item Item) error {
+	// The duplicates are checked against the committed items only, the batch is committed ahead of
+	// an item sharing the timestamp with a pending one. Otherwise, they would take the same sequence and overwrite each other.
+	if _, ok := c.pending[item.Time()]; ok {
+		if err := c.flush(); err != nil {
+			return err
+		}
+	}
+	c.pending[item.Time()] = struct{}{}
+	ts := time.Unix(0, int64(item.Time()))
+	span, err := c.target.Create(ts)
+	if err != nil {
+		return err
+	}
+	// the span is kept open until the batch is committed
+	c.spans = append(c.spans, span)
+	builder := span.WriterBuilder().Time(ts).Batch(c.batch)
+	for _, f := range c.opts.Families {
+		var val []byte
+		var errGet error
+		if f == nil {
+			val, errGet = item.Val()
+		} else {
+			val, errGet = item.Family(string(f))
+		}
+		// the family is absent if it's not written along with the item
+		if errGet != nil || val == nil {
+			continue
+		}
+		if f == nil {
+			builder.Val(val)
+		} else {
+			builder.Family(f, val)
+		}
+	}
+	writer, err := builder.Build()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(); err != nil {
+		return err
+	}
+	if c.opts.OnWrite != nil {
+		if err = c.opts.OnWrite(c.dst, item, writer); err != nil {
+			return err
+		}
+	}
+	if c.batch.Len() < reshardBatchSize {
+		return nil
+	}
+	return c.flush()
+}
+
+func (c *copier) flush() error {
+	err := c.batch.Commit()
+	for _, span := range c.spans {
+		err = multierr.Append(err, span.Close())
+	}
+	c.spans = c.spans[:0]
+	c.pending = make(map[uint64]struct{})
+	return err
+}
+
+// moveShards moves the shards in the location aside. Other files in the location, for example, the write-ahead logs
+// of the upper layer, are kept. The shards are moved to a temporary directory first to survive the interruption.
+func moveShards(location, dst string) error {
+	tmp := dst + movingSuffix
+	if err := os.MkdirAll(tmp, dirPerm); err != nil {
+		return errors.Wrapf(err, "failed to create %s", tmp)
+	}
+	var err error
+	if _, errStat := os.Stat(location); errStat == nil {
+		err = walkDir(location, shardPathPrefix, func(suffix, absolutePath string) error {
+			if errRename := os.Rename(absolutePath, fmt.Sprintf(dirTemplate, tmp, shardPathPrefix+suffix)); errRename != nil {
+				return errors.Wrapf(errRename, "failed to move %s aside", absolutePath)
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		return errors.Wrapf(err, "failed to move %s aside", location)
+	}
+	return nil
+}
+
+func removeShards(location string) error {
+	if _, err := os.Stat(location); os.IsNotExist(err) {
+		return nil
+	}
+	return walkDir(location, shardPathPrefix, func(_, absolutePath string) error {
+		if err := os.RemoveAll(absolutePath); err != nil {
+			return errors.Wrapf(err, "failed to clean up %s", absolutePath)
+		}
+		return nil
+	})
+}
+
+// shardNumInLocation returns the number of the shards according to the largest shard id in the location
+func shardNumInLocation(location string) (uint32, error) {
+	var num uint32
+	if _, err := os.Stat(location); os.IsNotExist(err) {
+		return 0, nil
+	}
+	err := walkDir(location, shardPathPrefix, func(suffix, _ string) error {
+		id, err := strconv.Atoi(suffix)
+		if err != nil {
+			return errors.Wrapf(err, "invalid shard id: %s", suffix)
+		}
+		if uint32(id)+1 > num {
+			num = uint32(id) + 1
+		}
+		return nil
+	})
+	return num, err
+}
@@ -0,0 +1,122 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"time"
+
+	"github.com/dgraph-io/ristretto/z"
+
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+)
+
+type IntervalUnit int
+
+const (
+	Hour IntervalUnit = iota
+	Day
+	Month
+)
+
+// CalendarInterval is a calendar-aware interval, months are not fixed durations
+type CalendarInterval struct {
+	Unit IntervalUnit
+	Num  int
+}
+
+func (ir CalendarInterval) NextTime(current time.Time) time.Time {
+	switch ir.Unit {
+	case Hour:
+		return current.Add(time.Hour * time.Duration(ir.Num))
+	case Day:
+		return current.AddDate(0, 0, ir.Num)
+	case Month:
+		return current.AddDate(0, ir.Num, 0)
+	}
+	panic("invalid interval unit")
+}
+
+func (ir CalendarInterval) PreviousTime(current time.Time) time.Time {
+	return CalendarInterval{Unit: ir.Unit, Num: -ir.Num}.NextTime(current)
+}
+
+// NewTTL converts the ttl in the schema to a CalendarInterval. Num is zero if the ttl is absent
+func NewTTL(duration *databasev1.Duration) CalendarInterval {
+	num := int(duration.GetVal())
+	switch duration.GetUnit() {
+	case databasev1.Duration_DURATION_UNIT_HOUR:
+		return CalendarInterval{Unit: Hour, Num: num}
+	case databasev1.Duration_DURATION_UNIT_DAY:
+		return CalendarInterval{Unit: Day, Num: num}
+	case databasev1.Duration_DURATION_UNIT_WEEK:
+		return CalendarInterval{Unit: Day, Num: num * 7}
+	case databasev1.Duration_DURATION_UNIT_MONTH:
+		return CalendarInterval{Unit: Month, Num: num}
+	}
+	return CalendarInterval{}
+}
+
+type retentionController struct {
+	shards   []*shard
+	ttl      CalendarInterval
+	interval time.Duration
+	closer   *z.Closer
+	l        *logger.Logger
+}
+
+func newRetentionController(shards []*shard, ttl CalendarInterval, interval time.Duration, l *logger.Logger) *retentionController {
+	return &retentionController{
+		shards:   shards,
+		ttl:      ttl,
+		interval: interval,
+		closer:   z.NewCloser(1),
+		l:        l.Named("retention"),
+	}
+}
+
+func (rc *retentionController) start() {
+	go func() {
+		defer rc.closer.Done()
+		ticker := time.NewTicker(rc.interval)
+		defer ticker.Stop()
+		rc.l.Info().Int("ttl", rc.ttl.Num).Int("unit", int(rc.ttl.Unit)).Dur("interval", rc.interval).Msg("started")
+		for {
+			select {
+			case <-rc.closer.HasBeenClosed():
+				return
+			case now := <-ticker.C:
+				rc.removeExpired(now)
+			}
+		}
+	}()
+}
+
+func (rc *retentionController) removeExpired(now time.Time) {
+	deadline := rc.ttl.PreviousTime(now)
+	rc.l.Debug().Time("deadline", deadline).Msg("remove expired segments")
+	for _, s := range rc.shards {
+		if err := s.segmentController.remove(deadline); err != nil {
+			rc.l.Error().Err(err).Uint("shard_id", uint(s.id)).Msg("failed to remove expired segments")
+		}
+	}
+}
+
+func (rc *retentionController) stop() {
+	rc.closer.SignalAndWait()
+}
@@ -19,25 +19,59 @@ package tsdb
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/dgraph-io/ristretto/z"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
 	"github.com/apache/skywalking-banyandb/banyand/kv"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 )
 
 type segment struct {
+	id   uint16
 	path string
 
 	lst         []*block
 	globalIndex kv.Store
-	sync.Mutex
-	l         *logger.Logger
-	startTime time.Time
-	endTime   time.Time
+	tombstone   *tombstone
+	barrier     writeBarrier
+	sync.RWMutex
+	l             *logger.Logger
+	blockCtx      context.Context
+	blockInterval time.Duration
+	startTime     time.Time
+	endTime       time.Time
+	inMemory      bool
+}
+
+// writeBarrier blocks the writes to the stores while they are being copied.
+// It's shared by the blocks in a segment, whose writes are rejected once the segment is read-only.
+type writeBarrier struct {
+	sync.RWMutex
+	// readOnly rejects the writes, the segments in the cold location are opened read-only
+	readOnly bool
+}
+
+// enter lets a write into the stores, the returned function should be invoked once it's done
+func (w *writeBarrier) enter() (func(), error) {
+	w.RLock()
+	if w.readOnly {
+		w.RUnlock()
+		return nil, ErrSegmentReadOnly
+	}
+	return w.RUnlock, nil
 }
 
 func (s *segment) contains(ts time.Time) bool {
+	s.RLock()
+	defer s.RUnlock()
 	greaterAndEqualStart := s.startTime.Equal(ts) || s.startTime.Before(ts)
 	if s.endTime.IsZero() {
 		return greaterAndEqualStart
@@ -45,46 +79,606 @@ func (s *segment) contains(ts time.Time) bool {
 	return greaterAndEqualStart && s.endTime.After(ts)
 }
 
-func newSegment(ctx context.Context, path string) (s *segment, err error) {
+func (s *segment) overlapping(timeRange TimeRange) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return timeRange.overlapping(s.startTime, s.endTime)
+}
+
+func (s *segment) expired(deadline time.Time) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return !s.endTime.IsZero() && !s.endTime.After(deadline)
+}
+
+type segmentOpts struct {
+	segID         uint16
+	path          string
+	startTime     time.Time
+	blockInterval time.Duration
+	readOnly      bool
+}
+
+func openSegment(ctx context.Context, opts segmentOpts) (s *segment, err error) {
 	s = &segment{
-		path:      path,
-		startTime: time.Now(),
+		id:            opts.segID,
+		path:          opts.path,
+		startTime:     opts.startTime,
+		blockInterval: opts.blockInterval,
 	}
+	s.barrier.readOnly = opts.readOnly
+	s.inMemory, _ = ctx.Value(inMemoryKey).(bool)
 	parentLogger := ctx.Value(logger.ContextKey)
 	if parentLogger != nil {
 		if pl, ok := parentLogger.(*logger.Logger); ok {
 			s.l = pl.Named("segment")
 		}
 	}
-	indexPath, err := mkdir(globalIndexTemplate, path)
+	if !opts.readOnly {
+		if err = removeManifest(s.path); err != nil {
+			return nil, err
+		}
+	}
+	indexPath, err := mkdirUnlessInMemory(ctx, globalIndexTemplate, s.path)
 	if err != nil {
 		return nil, err
 	}
-	if s.globalIndex, err = kv.OpenStore(0, indexPath, kv.StoreWithLogger(s.l)); err != nil {
+	var storeOpts []kv.StoreOptions
+	if opts.readOnly {
+		storeOpts = append(storeOpts, kv.StoreWithReadOnly())
+	}
+	if s.inMemory {
+		storeOpts = append(storeOpts, kv.StoreWithInMemory())
+	}
+	if s.globalIndex, err = kv.OpenStore(0, indexPath, append(storeOpts, kv.StoreWithLogger(s.l))...); err != nil {
+		return nil, err
+	}
+	if s.tombstone, err = openTombstone(fmt.Sprintf(tombstoneTemplate, s.path), s.l, storeOpts...); err != nil {
+		return nil, err
+	}
+	s.blockCtx = context.WithValue(ctx, logger.ContextKey, s.l)
+	if !s.inMemory {
+		err = walkDir(s.path, blockPathPrefix, func(suffix, absolutePath string) error {
+			blockStart, errParse := time.ParseInLocation(segFormat+blockFormat, s.startTime.Format(segFormat)+suffix, time.Local)
+			if errParse != nil {
+				return errors.Wrapf(errParse, "invalid block name: %s", suffix)
+			}
+			_, errOpen := s.openBlock(blockStart, absolutePath)
+			return errOpen
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(s.lst) > 0 || opts.readOnly {
+		return s, nil
+	}
+	blockStart := s.startTime
+	if now := time.Now(); now.Format(segFormat) == s.startTime.Format(segFormat) {
+		blockStart = s.blockStartTime(now)
+	}
+	if _, err = s.createBlock(blockStart); err != nil {
 		return nil, err
 	}
-	blockPath, err := mkdir(blockTemplate, path, time.Now().Format(blockFormat))
+	return s, nil
+}
+
+// blockStartTime aligns the time to the start of the block interval it belongs to
+func (s *segment) blockStartTime(ts time.Time) time.Time {
+	if s.blockInterval <= 0 {
+		return ts.Truncate(time.Minute)
+	}
+	return s.startTime.Add(ts.Sub(s.startTime) / s.blockInterval * s.blockInterval)
+}
+
+func (s *segment) createBlock(startTime time.Time) (*block, error) {
+	blockPath, err := mkdirUnlessInMemory(s.blockCtx, blockTemplate, s.path, startTime.Format(blockFormat))
 	if err != nil {
 		return nil, err
 	}
-	var b *block
-	if b, err = newBlock(context.WithValue(ctx, logger.ContextKey, s.l), blockOpts{
-		path: blockPath,
-	}); err != nil {
+	return s.openBlock(startTime, blockPath)
+}
+
+func (s *segment) openBlock(startTime time.Time, path string) (*block, error) {
+	s.Lock()
+	defer s.Unlock()
+	i := sort.Search(len(s.lst), func(i int) bool {
+		return !s.lst[i].startTime.Before(startTime)
+	})
+	if i < len(s.lst) && s.lst[i].startTime.Equal(startTime) {
+		return s.lst[i], nil
+	}
+	b, err := newBlock(s.blockCtx, blockOpts{
+		segID:     s.id,
+		blockID:   blockIDFromTime(startTime),
+		path:      path,
+		startTime: startTime,
+		tombstone: s.tombstone,
+		barrier:   &s.barrier,
+		readOnly:  s.isReadOnly(),
+	})
+	if err != nil {
 		return nil, err
 	}
-	{
-		s.Lock()
-		defer s.Unlock()
-		s.lst = append(s.lst, b)
+	if i > 0 {
+		s.lst[i-1].setEndTime(startTime)
 	}
-	return s, nil
+	if i < len(s.lst) {
+		b.setEndTime(s.lst[i].startTime)
+	} else if !s.endTime.IsZero() {
+		b.setEndTime(s.endTime)
+	}
+	s.lst = append(s.lst, nil)
+	copy(s.lst[i+1:], s.lst[i:])
+	s.lst[i] = b
+	return b, nil
 }
 
+// blockFor returns the block containing the time, a new one is created if it's before all blocks
+func (s *segment) blockFor(ts time.Time) (*block, error) {
+	if s.isReadOnly() {
+		return nil, errors.WithMessagef(ErrSegmentReadOnly, "segment: %s, time: %s", s.path, ts)
+	}
+	for _, b := range s.blocks() {
+		if b.contains(ts) {
+			return b, nil
+		}
+	}
+	return s.createBlock(s.blockStartTime(ts))
+}
+
+func (s *segment) isReadOnly() bool {
+	s.barrier.RLock()
+	defer s.barrier.RUnlock()
+	return s.barrier.readOnly
+}
+
+// setReadOnly waits for the ongoing writes, the following ones are rejected if it's read-only
+func (s *segment) setReadOnly(readOnly bool) {
+	s.barrier.Lock()
+	defer s.barrier.Unlock()
+	s.barrier.readOnly = readOnly
+}
+
+func (s *segment) blocks() []*block {
+	s.RLock()
+	defer s.RUnlock()
+	result := make([]*block, len(s.lst))
+	copy(result, s.lst)
+	return result
+}
+
+func (s *segment) block(id uint16) *block {
+	s.RLock()
+	defer s.RUnlock()
+	for _, b := range s.lst {
+		if b.blockID == id {
+			return b
+		}
+	}
+	return nil
+}
+
+func (s *segment) seal(endTime time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	s.endTime = endTime
+	if len(s.lst) > 0 {
+		s.lst[len(s.lst)-1].setEndTime(endTime)
+	}
+}
+
+// enterWrite lets a write into the stores, the returned function should be invoked once it's done
+func (s *segment) enterWrite() (func(), error) {
+	exit, err := s.barrier.enter()
+	if err != nil {
+		return nil, errors.WithMessagef(err, "segment: %s", s.path)
+	}
+	return exit, nil
+}
+
+// snapshot copies the stores after the ongoing writes are done, the new ones wait till it's done
+func (s *segment) snapshot(dir string) error {
+	s.barrier.Lock()
+	defer s.barrier.Unlock()
+	if err := s.globalIndex.Snapshot(fmt.Sprintf(globalIndexTemplate, dir)); err != nil {
+		return err
+	}
+	if err := s.tombstone.snapshot(fmt.Sprintf(tombstoneTemplate, dir)); err != nil {
+		return err
+	}
+	for _, b := range s.blocks() {
+		blockDir, err := mkdir(dirTemplate, dir, filepath.Base(b.path))
+		if err != nil {
+			return err
+		}
+		if err = b.snapshot(blockDir); err != nil {
+			return errors.WithMessagef(err, "failed to snapshot the block %s", b.path)
+		}
+	}
+	return nil
+}
+
+func (s *segment) stats() SegmentStats {
+	s.RLock()
+	result := SegmentStats{
+		ID:        s.id,
+		StartTime: s.startTime,
+		EndTime:   s.endTime,
+	}
+	s.RUnlock()
+	indexStats := s.globalIndex.Stats()
+	result.GlobalIndexTermCount = indexStats.KeyCount
+	result.DiskSize = indexStats.DiskSize + s.tombstone.store.Stats().DiskSize
+	for _, b := range s.blocks() {
+		bs := b.stats()
+		result.DiskSize += bs.DiskSize
+		result.ItemCount += bs.ItemCount
+		result.Blocks = append(result.Blocks, bs)
+	}
+	return result
+}
+
+// close writes the manifest of a sealed segment, which covers the segment-level stores
 func (s *segment) close() {
 	s.Lock()
 	defer s.Unlock()
 	for _, b := range s.lst {
 		b.close()
 	}
+	_ = s.globalIndex.Close()
+	_ = s.tombstone.close()
+	if s.endTime.IsZero() || s.inMemory {
+		return
+	}
+	if err := writeManifest(s.path, segmentDirs...); err != nil {
+		s.l.Warn().Err(err).Str("path", s.path).Msg("failed to write the manifest")
+	}
+}
+
+type segmentController struct {
+	sync.RWMutex
+	// maintenanceLock serializes the retention and the tiering, both of which take the segments out of the list
+	maintenanceLock sync.Mutex
+	ctx             context.Context
+	location        string
+	// coldLocation holds the aged segments, which are opened read-only. It's empty if the tiering is disabled
+	coldLocation   string
+	blockInterval  time.Duration
+	backfillWindow time.Duration
+	ttl            CalendarInterval
+	lst            []*segment
+	l              *logger.Logger
+}
+
+type segmentControllerOpts struct {
+	location string
+	// coldLocation is empty if the tiering is disabled
+	coldLocation   string
+	blockInterval  time.Duration
+	backfillWindow time.Duration
+	ttl            CalendarInterval
+}
+
+func newSegmentController(ctx context.Context, opts segmentControllerOpts) *segmentController {
+	sc := &segmentController{
+		ctx:            ctx,
+		location:       opts.location,
+		coldLocation:   opts.coldLocation,
+		blockInterval:  opts.blockInterval,
+		backfillWindow: opts.backfillWindow,
+		ttl:            opts.ttl,
+	}
+	parentLogger := ctx.Value(logger.ContextKey)
+	if parentLogger != nil {
+		if pl, ok := parentLogger.(*logger.Logger); ok {
+			sc.l = pl.Named("segment_controller")
+		}
+	}
+	if sc.l == nil {
+		sc.l = logger.GetLogger("segment_controller")
+	}
+	return sc
+}
+
+func (sc *segmentController) open() error {
+	// there is no segment to load in the memory
+	if inMemory, _ := sc.ctx.Value(inMemoryKey).(bool); inMemory {
+		_, err := sc.create(segmentStartTime(time.Now()))
+		return err
+	}
+	if err := sc.walk(sc.location, false); err != nil {
+		return err
+	}
+	if sc.coldLocation != "" {
+		if _, err := mkdir(sc.coldLocation); err != nil {
+			return err
+		}
+		// the hot one wins if a segment is in both locations, whose moving is interrupted
+		if err := sc.walk(sc.coldLocation, true); err != nil {
+			return err
+		}
+	}
+	if len(sc.segments()) > 0 {
+		return nil
+	}
+	_, err := sc.create(segmentStartTime(time.Now()))
+	return err
+}
+
+func (sc *segmentController) walk(location string, readOnly bool) error {
+	return walkDir(location, segPathPrefix, func(suffix, absolutePath string) error {
+		startTime, errParse := time.ParseInLocation(segFormat, suffix, time.Local)
+		if errParse != nil {
+			return errors.Wrapf(errParse, "invalid segment name: %s", suffix)
+		}
+		_, errOpen := sc.openSegment(startTime, absolutePath, readOnly)
+		return errOpen
+	})
+}
+
+func (sc *segmentController) create(startTime time.Time) (*segment, error) {
+	segPath, err := mkdirUnlessInMemory(sc.ctx, segTemplate, sc.location, startTime.Format(segFormat))
+	if err != nil {
+		return nil, err
+	}
+	return sc.openSegment(startTime, segPath, false)
+}
+
+func (sc *segmentController) openSegment(startTime time.Time, path string, readOnly bool) (*segment, error) {
+	sc.Lock()
+	defer sc.Unlock()
+	i := sort.Search(len(sc.lst), func(i int) bool {
+		return !sc.lst[i].startTime.Before(startTime)
+	})
+	if i < len(sc.lst) && sc.lst[i].startTime.Equal(startTime) {
+		return sc.lst[i], nil
+	}
+	seg, err := openSegment(sc.ctx, segmentOpts{
+		segID:         segIDFromTime(startTime),
+		path:          path,
+		startTime:     startTime,
+		blockInterval: sc.blockInterval,
+		readOnly:      readOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if i > 0 {
+		sc.lst[i-1].seal(startTime)
+	}
+	if i < len(sc.lst) {
+		seg.seal(sc.lst[i].startTime)
+	}
+	sc.lst = append(sc.lst, nil)
+	copy(sc.lst[i+1:], sc.lst[i:])
+	sc.lst[i] = seg
+	return seg, nil
+}
+
+// blockFor returns the block containing the time. The time beyond the ttl or the backfill window
+// is rejected even if a segment still contains it. The segment and the block are created
+// if the time is before all of them
+func (sc *segmentController) blockFor(ts time.Time) (*block, error) {
+	now := time.Now()
+	if sc.ttl.Num > 0 {
+		if deadline := sc.ttl.PreviousTime(now); ts.Before(deadline) {
+			return nil, errors.WithMessagef(ErrExpired, "time: %s, deadline: %s", ts, deadline)
+		}
+	}
+	if sc.backfillWindow > 0 {
+		if deadline := now.Add(-sc.backfillWindow); ts.Before(deadline) {
+			return nil, errors.WithMessagef(ErrOutOfBackfillWindow, "time: %s, deadline: %s", ts, deadline)
+		}
+	}
+	for _, s := range sc.segments() {
+		if s.contains(ts) {
+			return s.blockFor(ts)
+		}
+	}
+	s, err := sc.create(segmentStartTime(ts))
+	if err != nil {
+		return nil, err
+	}
+	sc.l.Info().Str("path", s.path).Time("time", ts).Msg("created a segment for the backfilled data")
+	return s.blockFor(ts)
+}
+
+func (sc *segmentController) segments() []*segment {
+	sc.RLock()
+	defer sc.RUnlock()
+	result := make([]*segment, len(sc.lst))
+	copy(result, sc.lst)
+	return result
+}
+
+func (sc *segmentController) get(id uint16) *segment {
+	sc.RLock()
+	defer sc.RUnlock()
+	for _, s := range sc.lst {
+		if s.id == id {
+			return s
+		}
+	}
+	return nil
+}
+
+func (sc *segmentController) latest() *segment {
+	sc.RLock()
+	defer sc.RUnlock()
+	if len(sc.lst) < 1 {
+		return nil
+	}
+	return sc.lst[len(sc.lst)-1]
+}
+
+// rotate opens a new segment at the beginning of every day, and a new block at every block interval
+func (sc *segmentController) rotate(closer *z.Closer) {
+	defer closer.Done()
+	for {
+		now := time.Now()
+		next := sc.nextRotation(now)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-closer.HasBeenClosed():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := sc.rotateAt(next); err != nil {
+			sc.l.Error().Err(err).Time("at", next).Msg("failed to rotate")
+		}
+	}
+}
+
+func (sc *segmentController) nextRotation(now time.Time) time.Time {
+	segStart := segmentStartTime(now)
+	next := segStart.AddDate(0, 0, 1)
+	if sc.blockInterval <= 0 {
+		return next
+	}
+	nextBlock := segStart.Add((now.Sub(segStart)/sc.blockInterval + 1) * sc.blockInterval)
+	if nextBlock.Before(next) {
+		return nextBlock
+	}
+	return next
+}
+
+func (sc *segmentController) rotateAt(ts time.Time) error {
+	if latest := sc.latest(); latest != nil && latest.startTime.Equal(segmentStartTime(ts)) {
+		if _, err := latest.createBlock(ts); err != nil {
+			return err
+		}
+		sc.l.Info().Str("segment", latest.path).Time("start_time", ts).Msg("created a new block")
+		return nil
+	}
+	seg, err := sc.create(segmentStartTime(ts))
+	if err != nil {
+		return err
+	}
+	sc.l.Info().Str("path", seg.path).Msg("created a new segment")
+	return nil
+}
+
+// remove closes and deletes the sealed segments ending before the deadline
+func (sc *segmentController) remove(deadline time.Time) (err error) {
+	sc.maintenanceLock.Lock()
+	defer sc.maintenanceLock.Unlock()
+	sc.Lock()
+	expired := make([]*segment, 0)
+	kept := make([]*segment, 0, len(sc.lst))
+	for _, s := range sc.lst {
+		if s.expired(deadline) {
+			expired = append(expired, s)
+			continue
+		}
+		kept = append(kept, s)
+	}
+	sc.lst = kept
+	sc.Unlock()
+	for _, s := range expired {
+		s.close()
+		if errRemove := os.RemoveAll(s.path); errRemove != nil {
+			err = multierr.Append(err, errors.Wrapf(errRemove, "failed to remove the segment %s", s.path))
+			continue
+		}
+		sc.l.Info().Str("path", s.path).Time("end_time", s.endTime).Msg("removed an expired segment")
+	}
+	return err
+}
+
+// tier moves the sealed segments ending before the deadline to the cold location, and reopens them read-only.
+// A segment is copied to the cold location before replacing the hot one, the queries always find it.
+func (sc *segmentController) tier(deadline time.Time) (err error) {
+	if sc.coldLocation == "" {
+		return nil
+	}
+	sc.maintenanceLock.Lock()
+	defer sc.maintenanceLock.Unlock()
+	for _, s := range sc.segments() {
+		if s.isReadOnly() || !s.expired(deadline) {
+			continue
+		}
+		if errMove := sc.moveToCold(s); errMove != nil {
+			err = multierr.Append(err, errors.WithMessagef(errMove, "failed to move the segment %s", s.path))
+		}
+	}
+	return err
+}
+
+func (sc *segmentController) moveToCold(s *segment) error {
+	// drains the ongoing writes and rejects the following ones, which would be lost after copying
+	s.setReadOnly(true)
+	coldPath := fmt.Sprintf(dirTemplate, sc.coldLocation, filepath.Base(s.path))
+	// clean up the leftover of an interrupted moving
+	if err := os.RemoveAll(coldPath); err != nil {
+		s.setReadOnly(false)
+		return errors.Wrapf(err, "failed to clean up %s", coldPath)
+	}
+	if err := s.snapshot(coldPath); err != nil {
+		s.setReadOnly(false)
+		return err
+	}
+	cold, err := openSegment(sc.ctx, segmentOpts{
+		segID:         s.id,
+		path:          coldPath,
+		startTime:     s.startTime,
+		blockInterval: sc.blockInterval,
+		readOnly:      true,
+	})
+	if err != nil {
+		s.setReadOnly(false)
+		return err
+	}
+	s.RLock()
+	endTime := s.endTime
+	s.RUnlock()
+	cold.seal(endTime)
+	sc.Lock()
+	swapped := false
+	for i, seg := range sc.lst {
+		if seg == s {
+			sc.lst[i] = cold
+			swapped = true
+			break
+		}
+	}
+	sc.Unlock()
+	// the segment was dropped while it's being copied, so is the copy
+	if !swapped {
+		cold.close()
+		if err = os.RemoveAll(coldPath); err != nil {
+			return errors.Wrapf(err, "failed to remove the copy of the dropped segment %s", coldPath)
+		}
+		return nil
+	}
+	// waits for the ongoing queries
+	s.close()
+	if err = os.RemoveAll(s.path); err != nil {
+		return errors.Wrapf(err, "failed to remove the moved segment %s", s.path)
+	}
+	sc.l.Info().Str("path", s.path).Str("cold_path", coldPath).Time("end_time", endTime).Msg("moved a segment to the cold location")
+	return nil
+}
+
+func (sc *segmentController) close() {
+	sc.Lock()
+	defer sc.Unlock()
+	for _, s := range sc.lst {
+		s.close()
+	}
+}
+
+// segIDFromTime returns the days since the Unix epoch, which keeps segment ids stable once old segments are removed
+func segIDFromTime(startTime time.Time) uint16 {
+	return uint16(time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, time.UTC).Unix() / int64(24*time.Hour/time.Second))
+}
+
+func blockIDFromTime(startTime time.Time) uint16 {
+	return uint16(startTime.Hour()*60 + startTime.Minute())
+}
+
+func segmentStartTime(ts time.Time) time.Time {
+	ts = ts.Local()
+	return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.Local)
 }
@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"math"
 	"time"
 
 	"github.com/pkg/errors"
@@ -34,8 +35,47 @@ import (
 var (
 	ErrEmptySeriesSpan = errors.New("there is no data in such time range")
 	ErrItemIDMalformed = errors.New("serialized item id is malformed")
+	ErrBlockAbsent     = errors.New("block is absent")
+	ErrItemIDOverflow  = errors.New("the item id overflows")
 )
 
+const (
+	// sequencedItemFlag marks the ids carrying a sequence suffix, which is beyond any timestamp the item id takes
+	sequencedItemFlag uint64 = 1 << 62
+	// the bits of the sequence suffix, the offset of the time to the block start takes the bits ahead of it
+	itemSequenceBits        = 10
+	maxItemSequence         = 1<<itemSequenceBits - 1
+	maxItemOffset    uint64 = 1<<(62-itemSequenceBits) - 1
+)
+
+// newItemID returns the id of an item written at ts in the block starting at blockStart.
+// The first item of a series at ts takes ts as its id, the following ones kept by the duplicate policy take
+// the offset of ts to the block start along with the sequence as a suffix.
+func newItemID(ts, blockStart time.Time, seq int) (common.ItemID, error) {
+	unixNano := ts.UnixNano()
+	if unixNano < 0 || uint64(unixNano) >= sequencedItemFlag {
+		return 0, errors.Wrapf(ErrItemIDOverflow, "time: %s", ts)
+	}
+	if seq == 0 {
+		return common.ItemID(unixNano), nil
+	}
+	offset := uint64(ts.Sub(blockStart))
+	if seq > maxItemSequence || offset > maxItemOffset {
+		return 0, errors.Wrapf(ErrItemIDOverflow, "time: %s, sequence: %d", ts, seq)
+	}
+	return common.ItemID(sequencedItemFlag | offset<<itemSequenceBits | uint64(seq)), nil
+}
+
+// parseItemID returns the time and the sequence of an item in the block starting at blockStart
+func parseItemID(id common.ItemID, blockStart time.Time) (ts uint64, seq uint16) {
+	v := uint64(id)
+	if v&sequencedItemFlag == 0 {
+		return v, 0
+	}
+	v &^= sequencedItemFlag
+	return uint64(blockStart.UnixNano()) + v>>itemSequenceBits, uint16(v & maxItemSequence)
+}
+
 type GlobalItemID struct {
 	ShardID  common.ShardID
 	segID    uint16
@@ -84,6 +124,14 @@ func (t TimeRange) contains(unixNano uint64) bool {
 	return tp.Equal(t.Start) || tp.After(t.Start)
 }
 
+func (t TimeRange) overlapping(start, end time.Time) bool {
+	startedBeforeEnd := start.Before(t.End) || start.Equal(t.Start)
+	if end.IsZero() {
+		return startedBeforeEnd
+	}
+	return startedBeforeEnd && end.After(t.Start)
+}
+
 func NewTimeRange(Start, End time.Time) TimeRange {
 	return TimeRange{
 		Start: Start,
@@ -98,9 +146,16 @@ func NewTimeRangeDuration(Start time.Time, Duration time.Duration) TimeRange {
 	}
 }
 
+// AnyTimeRange covers all the data
+var AnyTimeRange = NewTimeRange(time.Unix(0, 0), time.Unix(0, math.MaxInt64))
+
 type Series interface {
 	ID() common.SeriesID
+	// Entity returns the original entity of the series, it's nil if the entity isn't stored or the series is got by its id
+	Entity() Entity
 	Span(timeRange TimeRange) (SeriesSpan, error)
+	// Create returns a span to write the data at the time, the block covering the time is created if it's absent
+	Create(t time.Time) (SeriesSpan, error)
 	Get(id GlobalItemID) (Item, io.Closer, error)
 }
 
@@ -108,12 +163,15 @@ type SeriesSpan interface {
 	io.Closer
 	WriterBuilder() WriterBuilder
 	SeekerBuilder() SeekerBuilder
+	// Delete removes the data of the series in the time range of the span
+	Delete() error
 }
 
 var _ Series = (*series)(nil)
 
 type series struct {
 	id      common.SeriesID
+	entity  Entity
 	blockDB blockDatabase
 	shardID common.ShardID
 	l       *logger.Logger
@@ -121,10 +179,14 @@ type series struct {
 
 func (s *series) Get(id GlobalItemID) (Item, io.Closer, error) {
 	b := s.blockDB.block(id)
+	if b == nil {
+		return nil, nil, errors.WithMessagef(ErrBlockAbsent, "id: %v", id)
+	}
 	return &item{
-		data:     b.dataReader(),
-		itemID:   id.ID,
-		seriesID: s.id,
+		data:       b.dataReader(),
+		itemID:     id.ID,
+		seriesID:   s.id,
+		blockStart: b.startTime(),
 	}, b, nil
 }
 
@@ -132,6 +194,10 @@ func (s *series) ID() common.SeriesID {
 	return s.id
 }
 
+func (s *series) Entity() Entity {
+	return s.entity
+}
+
 func (s *series) Span(timeRange TimeRange) (SeriesSpan, error) {
 	blocks := s.blockDB.span(timeRange)
 	if len(blocks) < 1 {
@@ -143,9 +209,21 @@ func (s *series) Span(timeRange TimeRange) (SeriesSpan, error) {
 	return newSeriesSpan(context.WithValue(context.Background(), logger.ContextKey, s.l), timeRange, blocks, s.id, s.shardID), nil
 }
 
-func newSeries(ctx context.Context, id common.SeriesID, blockDB blockDatabase) *series {
+func (s *series) Create(t time.Time) (SeriesSpan, error) {
+	b, err := s.blockDB.create(t)
+	if err != nil {
+		return nil, err
+	}
+	s.l.Debug().
+		Time("time", t).
+		Msg("create series span")
+	return newSeriesSpan(context.WithValue(context.Background(), logger.ContextKey, s.l), NewTimeRangeDuration(t, 0), []blockDelegate{b}, s.id, s.shardID), nil
+}
+
+func newSeries(ctx context.Context, id common.SeriesID, entity Entity, blockDB blockDatabase) *series {
 	s := &series{
 		id:      id,
+		entity:  entity,
 		blockDB: blockDB,
 		shardID: blockDB.shardID(),
 	}
@@ -166,9 +244,14 @@ type seriesSpan struct {
 	shardID   common.ShardID
 	timeRange TimeRange
 	l         *logger.Logger
+	// scanners scan the blocks, which have to be released ahead of the blocks
+	scanners []io.Closer
 }
 
 func (s *seriesSpan) Close() (err error) {
+	for _, scanner := range s.scanners {
+		err = multierr.Append(err, scanner.Close())
+	}
 	for _, delegate := range s.blocks {
 		err = multierr.Append(err, delegate.Close())
 	}
@@ -183,6 +266,19 @@ func (s *seriesSpan) SeekerBuilder() SeekerBuilder {
 	return newSeekerBuilder(s)
 }
 
+func (s *seriesSpan) Delete() (err error) {
+	for _, b := range s.blocks {
+		err = multierr.Append(err, b.delete(s.seriesID, s.timeRange))
+	}
+	if err == nil {
+		s.l.Info().
+			Uint64("series_id", uint64(s.seriesID)).
+			Times("time_range", []time.Time{s.timeRange.Start, s.timeRange.End}).
+			Msg("deleted the data in the time range")
+	}
+	return err
+}
+
 func newSeriesSpan(ctx context.Context, timeRange TimeRange, blocks []blockDelegate, id common.SeriesID, shardID common.ShardID) *seriesSpan {
 	s := &seriesSpan{
 		blocks:    blocks,
@@ -18,6 +18,8 @@
 package tsdb
 
 import (
+	"time"
+
 	"github.com/apache/skywalking-banyandb/api/common"
 	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
@@ -109,10 +111,12 @@ type item struct {
 	data        kv.TimeSeriesReader
 	seriesID    common.SeriesID
 	sortedField []byte
+	blockStart  time.Time
 }
 
 func (i *item) Time() uint64 {
-	return uint64(i.itemID)
+	ts, _ := parseItemID(i.itemID, i.blockStart)
+	return ts
 }
 
 func (i *item) SortedField() []byte {
@@ -120,18 +124,21 @@ func (i *item) SortedField() []byte {
 }
 
 func (i *item) Family(family string) ([]byte, error) {
-	d := dataBucket{
-		seriesID: i.seriesID,
-		family:   []byte(family),
-	}
-	return i.data.Get(d.marshal(), uint64(i.itemID))
+	return i.get([]byte(family))
 }
 
 func (i *item) Val() ([]byte, error) {
+	return i.get(nil)
+}
+
+func (i *item) get(family []byte) ([]byte, error) {
+	ts, seq := parseItemID(i.itemID, i.blockStart)
 	d := dataBucket{
 		seriesID: i.seriesID,
+		family:   family,
+		seq:      seq,
 	}
-	return i.data.Get(d.marshal(), uint64(i.itemID))
+	return i.data.Get(d.marshal(), ts)
 }
 
 func (i *item) ID() common.ItemID {
@@ -135,4 +135,16 @@ func (s *seekerBuilder) buildIndexFilter(block blockDelegate, conditions []condW
 	}, nil
 }
 
+func (s *seekerBuilder) buildTombstoneFilter(block blockDelegate) filterFn {
+	ranges := block.deletedRanges(s.seriesSpan.seriesID)
+	if len(ranges) < 1 {
+		return nil
+	}
+	return func(item Item) bool {
+		valid := !deleted(ranges, item.Time())
+		s.seriesSpan.l.Trace().Bool("valid", valid).Msg("filter item by tombstones")
+		return valid
+	}
+}
+
 type filterFn func(item Item) bool
@@ -19,6 +19,7 @@ package tsdb
 
 import (
 	"sort"
+	"sync"
 	"time"
 
 	"go.uber.org/multierr"
@@ -33,8 +34,6 @@ import (
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 )
 
-var emptyFilters = make([]filterFn, 0)
-
 func (s *seekerBuilder) OrderByIndex(indexRule *databasev1.IndexRule, order modelv1.Sort) SeekerBuilder {
 	s.indexRuleForSorting = indexRule
 	s.order = order
@@ -78,6 +77,9 @@ func (s *seekerBuilder) buildSeriesByIndex(conditions []condWithIRT) (series []I
 		if filter != nil {
 			filters = append(filters, filter)
 		}
+		if filter = s.buildTombstoneFilter(b); filter != nil {
+			filters = append(filters, filter)
+		}
 		switch s.indexRuleForSorting.GetType() {
 		case databasev1.IndexRule_TYPE_TREE:
 			inner, err = b.lsmIndexReader().Iterator(fieldKey, s.rangeOptsForSorting, s.order)
@@ -88,7 +90,7 @@ func (s *seekerBuilder) buildSeriesByIndex(conditions []condWithIRT) (series []I
 			return nil, err
 		}
 		if inner != nil {
-			series = append(series, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), s.seriesSpan.seriesID, filters))
+			series = append(series, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), s.seriesSpan.seriesID, b.startTime(), filters))
 		}
 	}
 	return
@@ -133,11 +135,16 @@ func (s *seekerBuilder) buildSeriesByTime(conditions []condWithIRT) ([]Iterator,
 			if err != nil {
 				return nil, err
 			}
-			if filter == nil {
-				delegated = append(delegated, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), s.seriesSpan.seriesID, emptyFilters))
-			} else {
-				delegated = append(delegated, newSearcherIterator(s.seriesSpan.l, inner, b.dataReader(), s.seriesSpan.seriesID, []filterFn{filter}))
+			filters := make([]filterFn, 0, 2)
+			if filter != nil {
+				filters = append(filters, filter)
+			}
+			if filter = s.buildTombstoneFilter(b); filter != nil {
+				filters = append(filters, filter)
 			}
+			data := newScanReader(b.dataReader(), timeRange, s.order)
+			s.seriesSpan.scanners = append(s.seriesSpan.scanners, data)
+			delegated = append(delegated, newSearcherIterator(s.seriesSpan.l, inner, data, s.seriesSpan.seriesID, b.startTime(), filters))
 		}
 	}
 	s.seriesSpan.l.Debug().
@@ -157,6 +164,7 @@ type searcherIterator struct {
 	cur           posting.Iterator
 	data          kv.TimeSeriesReader
 	seriesID      common.SeriesID
+	blockStart    time.Time
 	filters       []filterFn
 	l             *logger.Logger
 }
@@ -193,24 +201,101 @@ func (s *searcherIterator) Val() Item {
 		itemID:      s.cur.Current(),
 		data:        s.data,
 		seriesID:    s.seriesID,
+		blockStart:  s.blockStart,
 	}
 }
 
+// Close releases the scanning reader created by the seeker, the block's own store is left open
 func (s *searcherIterator) Close() error {
+	if r, ok := s.data.(*scanReader); ok {
+		return multierr.Append(s.fieldIterator.Close(), r.Close())
+	}
 	return s.fieldIterator.Close()
 }
 
 func newSearcherIterator(l *logger.Logger, fieldIterator index.FieldIterator, data kv.TimeSeriesReader,
-	seriesID common.SeriesID, filters []filterFn) Iterator {
+	seriesID common.SeriesID, blockStart time.Time, filters []filterFn) Iterator {
 	return &searcherIterator{
 		fieldIterator: fieldIterator,
 		data:          data,
 		seriesID:      seriesID,
+		blockStart:    blockStart,
 		filters:       filters,
 		l:             l,
 	}
 }
 
+var _ kv.TimeSeriesReader = (*scanReader)(nil)
+
+// scanReader reads the values of the items sorted by time through scanning their keys, instead of looking up them one by one.
+// The items read out of the order are looked up by the delegated reader, so are the ones read after the span is closed,
+// because the scanning holds the tables of the block which might be closed once the span releases it.
+type scanReader struct {
+	kv.TimeSeriesReader
+	startTs uint64
+	endTs   uint64
+	reverse bool
+	cursors map[string]*scanCursor
+	lock    sync.Mutex
+}
+
+type scanCursor struct {
+	iter  kv.TimeSeriesIterator
+	valid bool
+}
+
+func newScanReader(delegated kv.TimeSeriesReader, timeRange TimeRange, order modelv1.Sort) *scanReader {
+	return &scanReader{
+		TimeSeriesReader: delegated,
+		startTs:          uint64(timeRange.Start.UnixNano()),
+		endTs:            uint64(timeRange.End.UnixNano()),
+		reverse:          order == modelv1.Sort_SORT_DESC,
+		cursors:          make(map[string]*scanCursor),
+	}
+}
+
+func (r *scanReader) Get(key []byte, ts uint64) ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.cursors == nil {
+		return r.TimeSeriesReader.Get(key, ts)
+	}
+	c, ok := r.cursors[string(key)]
+	if !ok {
+		iter, err := r.TimeSeriesReader.Scan(key, r.startTs, r.endTs, r.reverse)
+		if err != nil {
+			return nil, err
+		}
+		c = &scanCursor{iter: iter, valid: iter.Next()}
+		r.cursors[string(key)] = c
+	}
+	for c.valid && r.before(c.iter.Time(), ts) {
+		c.valid = c.iter.Next()
+	}
+	if c.valid && c.iter.Time() == ts {
+		return c.iter.Val(), nil
+	}
+	return r.TimeSeriesReader.Get(key, ts)
+}
+
+func (r *scanReader) before(a, b uint64) bool {
+	if r.reverse {
+		return a > b
+	}
+	return a < b
+}
+
+func (r *scanReader) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var err error
+	for _, c := range r.cursors {
+		err = multierr.Append(err, c.iter.Close())
+	}
+	r.cursors = nil
+	return err
+}
+
 var _ Iterator = (*mergedIterator)(nil)
 
 type mergedIterator struct {
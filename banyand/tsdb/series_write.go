@@ -32,6 +32,12 @@ type WriterBuilder interface {
 	Family(name []byte, val []byte) WriterBuilder
 	Time(ts time.Time) WriterBuilder
 	Val(val []byte) WriterBuilder
+	// Batch defers the writes to the batch, which are persistent once the batch is committed
+	Batch(batch Batch) WriterBuilder
+	// Source sets the identity of the item in its source, for example, the sequence of a write-ahead log entry.
+	// Writing an item whose source is found at the same time again takes the found one's id
+	// regardless of the duplicate policy, which makes replaying the source idempotent.
+	Source(source []byte) WriterBuilder
 	Build() (Writer, error)
 }
 
@@ -52,6 +58,8 @@ type writerBuilder struct {
 	}
 	ts            time.Time
 	seriesIDBytes []byte
+	batch         *batch
+	source        []byte
 }
 
 func (w *writerBuilder) Family(name []byte, val []byte) WriterBuilder {
@@ -81,10 +89,21 @@ func (w *writerBuilder) Val(val []byte) WriterBuilder {
 	return w
 }
 
+func (w *writerBuilder) Batch(b Batch) WriterBuilder {
+	w.batch, _ = b.(*batch)
+	return w
+}
+
+func (w *writerBuilder) Source(source []byte) WriterBuilder {
+	w.source = source
+	return w.Family(sourceFamily, source)
+}
+
 var ErrNoTime = errors.New("no time specified")
 var ErrNoVal = errors.New("no value specified")
 
 var ErrDuplicatedFamily = errors.New("duplicated family")
+var ErrDuplicatedItem = errors.New("an item with the same time exists in the series")
 
 func (w *writerBuilder) Build() (Writer, error) {
 	if w.block == nil {
@@ -104,9 +123,10 @@ func (w *writerBuilder) Build() (Writer, error) {
 		}
 	}
 	segID, blockID := w.block.identity()
-	return &writer{
-		block: w.block,
-		ts:    w.ts,
+	wr := &writer{
+		block:    w.block,
+		delegate: w.block,
+		ts:       w.ts,
 		itemID: &GlobalItemID{
 			ShardID:  w.series.shardID,
 			segID:    segID,
@@ -115,7 +135,13 @@ func (w *writerBuilder) Build() (Writer, error) {
 			ID:       common.ItemID(uint64(w.ts.UnixNano())),
 		},
 		columns: w.values,
-	}, nil
+		source:  w.source,
+	}
+	if w.batch != nil {
+		wr.batch = w.batch
+		wr.block = w.batch.block(wr.itemID, w.block)
+	}
+	return wr, nil
 }
 
 func newWriterBuilder(seriesSpan *seriesSpan) WriterBuilder {
@@ -128,13 +154,16 @@ func newWriterBuilder(seriesSpan *seriesSpan) WriterBuilder {
 var _ Writer = (*writer)(nil)
 
 type writer struct {
-	block   blockDelegate
-	ts      time.Time
-	columns []struct {
+	block    blockWriter
+	delegate blockDelegate
+	batch    *batch
+	ts       time.Time
+	columns  []struct {
 		family []byte
 		val    []byte
 	}
 	itemID *GlobalItemID
+	source []byte
 }
 
 func (w *writer) ItemID() GlobalItemID {
@@ -142,36 +171,75 @@ func (w *writer) ItemID() GlobalItemID {
 }
 
 func (w *writer) WriteLSMIndex(field index.Field) error {
+	exit, err := w.delegate.enterWrite()
+	if err != nil {
+		return err
+	}
+	defer exit()
 	field.Key.SeriesID = w.itemID.SeriesID
 	return w.block.writeLSMIndex(field, w.itemID.ID)
 }
 
 func (w *writer) WriteInvertedIndex(field index.Field) error {
+	exit, err := w.delegate.enterWrite()
+	if err != nil {
+		return err
+	}
+	defer exit()
 	field.Key.SeriesID = w.itemID.SeriesID
 	return w.block.writeInvertedIndex(field, w.itemID.ID)
 }
 
+// sourceFamily keeps the source of an item, which is reserved since a family of the schema always has a name
+var sourceFamily = []byte("\x00source")
+
 type dataBucket struct {
 	seriesID common.SeriesID
 	family   []byte
+	// seq is the sequence of an item kept by the duplicate policy, whose data is written to its own key
+	seq uint16
 }
 
 func (d dataBucket) marshal() []byte {
-	if d.family == nil {
+	if d.family == nil && d.seq == 0 {
 		return d.seriesID.Marshal()
 	}
-	return bytes.Join([][]byte{
-		d.seriesID.Marshal(),
-		hash(d.family),
-	}, nil)
+	parts := [][]byte{d.seriesID.Marshal()}
+	if d.family != nil {
+		parts = append(parts, hash(d.family))
+	}
+	if d.seq > 0 {
+		parts = append(parts, convert.Uint16ToBytes(d.seq))
+	}
+	return bytes.Join(parts, nil)
 }
 
+// Write writes the item following the duplicate policy. The item id is decided here, which might be different
+// from the one returned by ItemID before writing. For a batched item, the duplicates are only checked against the
+// committed ones.
 func (w *writer) Write() (GlobalItemID, error) {
+	// the data and the primary index of an item are copied together by a snapshot
+	exit, err := w.delegate.enterWrite()
+	if err != nil {
+		return w.ItemID(), err
+	}
+	defer exit()
+	itemID, release, err := w.delegate.allocateItemID(w.itemID.SeriesID, w.ts, w.source)
+	if err != nil {
+		return w.ItemID(), err
+	}
+	defer release()
+	w.itemID.ID = itemID
 	id := w.ItemID()
+	if w.batch != nil {
+		w.batch.add()
+	}
+	_, seq := parseItemID(id.ID, w.delegate.startTime())
 	for _, c := range w.columns {
 		err := w.block.write(dataBucket{
 			seriesID: w.itemID.SeriesID,
 			family:   c.family,
+			seq:      seq,
 		}.marshal(),
 			c.val, w.ts)
 		if err != nil {
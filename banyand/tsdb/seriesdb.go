@@ -20,10 +20,14 @@ package tsdb
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"math"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 
 	"github.com/apache/skywalking-banyandb/api/common"
@@ -37,10 +41,34 @@ var zeroIntBytes = convert.Uint64ToBytes(0)
 
 var AnyEntry = Entry(nil)
 
+var ErrSeriesMetadataMalformed = errors.New("serialized series metadata is malformed")
+
+var ErrSeriesAmbiguous = errors.New("several series share the hash key")
+
 type Entry []byte
 
 type Entity []Entry
 
+func (e Entity) Equal(other Entity) bool {
+	if len(e) != len(other) {
+		return false
+	}
+	for i, entry := range e {
+		if !bytes.Equal(entry, other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e Entity) String() string {
+	entries := make([]string, len(e))
+	for i, entry := range e {
+		entries[i] = fmt.Sprintf("%x", []byte(entry))
+	}
+	return strings.Join(entries, "|")
+}
+
 func (e Entity) Marshal() []byte {
 	data := make([][]byte, len(e))
 	for i, entry := range e {
@@ -54,12 +82,15 @@ type Path struct {
 	mask     []byte
 	template []byte
 	isFull   bool
+	// entries filter the series whose hash keys collide
+	entries []Entry
 }
 
 func NewPath(entries []Entry) Path {
 	p := Path{
 		mask:     make([]byte, 0),
 		template: make([]byte, 0),
+		entries:  entries,
 	}
 
 	var offset int
@@ -85,17 +116,37 @@ func NewPath(entries []Entry) Path {
 	return p
 }
 
+// match checks the entity against the entries of the path. A series without the entity is matched
+// since it can't be told apart from others.
+func (p Path) match(entity Entity) bool {
+	if entity == nil {
+		return true
+	}
+	if len(entity) != len(p.entries) {
+		return false
+	}
+	for i, e := range p.entries {
+		if e != nil && !bytes.Equal(e, entity[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 type SeriesDatabase interface {
 	io.Closer
 	GetByID(id common.SeriesID) (Series, error)
 	Get(entity Entity) (Series, error)
 	GetByHashKey(key []byte) (Series, error)
 	List(path Path) (SeriesList, error)
+	// Delete removes all the data of the series written before the deletion
+	Delete(entity Entity) error
 }
 
 type blockDatabase interface {
 	shardID() common.ShardID
 	span(timeRange TimeRange) []blockDelegate
+	create(ts time.Time) (blockDelegate, error)
 	block(id GlobalItemID) blockDelegate
 }
 
@@ -106,35 +157,112 @@ type seriesDB struct {
 	sync.Mutex
 	l *logger.Logger
 
-	lst            []*segment
+	segCtrl        *segmentController
 	seriesMetadata kv.Store
-	sID            common.ShardID
+	// seriesIDs maps the series id to its hash key to detect id collisions
+	seriesIDs kv.Store
+	sID       common.ShardID
 }
 
+// GetByHashKey fails with ErrSeriesAmbiguous if several series share the key.
 func (s *seriesDB) GetByHashKey(key []byte) (Series, error) {
-	seriesID, err := s.seriesMetadata.Get(key)
-	if err != nil && err != kv.ErrKeyNotFound {
+	return s.getOrCreate(key, nil)
+}
+
+// getOrCreate looks up the series by the hash key, a new one is created if it's absent.
+// The entity is stored along with the series id if it's provided. The entities whose hash keys
+// collide are stored under the same key with distinct series ids.
+func (s *seriesDB) getOrCreate(key []byte, entity Entity) (Series, error) {
+	records, err := s.records(key)
+	if err != nil {
 		return nil, err
 	}
-	if err == nil {
-		return newSeries(s.context(), bytesConvSeriesID(seriesID), s), nil
+	if entity == nil && len(records) > 1 {
+		return nil, errors.WithMessagef(ErrSeriesAmbiguous, "key: %x", key)
+	}
+	if r, ok := matchRecord(records, entity); ok && (r.entity != nil || entity == nil) {
+		return newSeries(s.context(), r.id, r.entity, s), nil
 	}
 	s.Lock()
 	defer s.Unlock()
-	seriesID = hash(key)
-	err = s.seriesMetadata.Put(key, seriesID)
+	// other writers might create the series before holding the lock
+	if records, err = s.records(key); err != nil {
+		return nil, err
+	}
+	if entity == nil && len(records) > 1 {
+		return nil, errors.WithMessagef(ErrSeriesAmbiguous, "key: %x", key)
+	}
+	if r, ok := matchRecord(records, entity); ok && (r.entity != nil || entity == nil) {
+		return newSeries(s.context(), r.id, r.entity, s), nil
+	}
+	var r seriesRecord
+	if len(records) == 1 && records[0].entity == nil {
+		// the series was created without the entity, store the entity along with the existing id
+		r = seriesRecord{id: records[0].id, entity: entity}
+		records[0] = r
+	} else {
+		if len(records) > 0 {
+			s.l.Warn().Hex("key", key).Str("entity", entity.String()).Str("existing_entity", records[0].entity.String()).
+				Msg("detected a hash key collision between entities")
+		}
+		if r.id, err = s.allocateID(key); err != nil {
+			return nil, err
+		}
+		r.entity = entity
+		records = append(records, r)
+	}
+	if err = s.seriesIDs.Put(r.id.Marshal(), key); err != nil {
+		return nil, err
+	}
+	if err = s.seriesMetadata.Put(key, marshalSeriesMetadata(records)); err != nil {
+		return nil, err
+	}
+	return newSeries(s.context(), r.id, r.entity, s), nil
+}
+
+// allocateID returns the hash of the key as the series id. If the id is taken by another key,
+// a distinct one is derived from it.
+func (s *seriesDB) allocateID(key []byte) (common.SeriesID, error) {
+	candidate := hash(key)
+	for {
+		existingKey, err := s.seriesIDs.Get(candidate)
+		if err == kv.ErrKeyNotFound {
+			return bytesConvSeriesID(candidate), nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		s.l.Warn().Hex("key", key).Hex("existing_key", existingKey).Uint64("series_id", convert.BytesToUint64(candidate)).
+			Msg("detected a series id collision, derive a distinct id")
+		candidate = hash(append(candidate, key...))
+	}
+}
+
+func (s *seriesDB) records(key []byte) ([]seriesRecord, error) {
+	val, err := s.seriesMetadata.Get(key)
+	if err == kv.ErrKeyNotFound {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	return newSeries(s.context(), bytesConvSeriesID(seriesID), s), nil
+	return unmarshalSeriesMetadata(val)
 }
 
 func (s *seriesDB) GetByID(id common.SeriesID) (Series, error) {
-	return newSeries(s.context(), id, s), nil
+	return newSeries(s.context(), id, nil, s), nil
 }
 
 func (s *seriesDB) block(id GlobalItemID) blockDelegate {
-	return s.lst[id.segID].lst[id.blockID].delegate()
+	seg := s.segCtrl.get(id.segID)
+	if seg == nil {
+		return nil
+	}
+	b := seg.block(id.blockID)
+	if b == nil {
+		return nil
+	}
+	return b.delegate()
 }
 
 func (s *seriesDB) shardID() common.ShardID {
@@ -142,23 +270,21 @@ func (s *seriesDB) shardID() common.ShardID {
 }
 
 func (s *seriesDB) Get(entity Entity) (Series, error) {
-	key := HashEntity(entity)
-	return s.GetByHashKey(key)
+	return s.getOrCreate(HashEntity(entity), entity)
 }
 
 func (s *seriesDB) List(path Path) (SeriesList, error) {
 	if path.isFull {
-		id, err := s.seriesMetadata.Get(path.prefix)
+		val, err := s.seriesMetadata.Get(path.prefix)
 		if err != nil && err != kv.ErrKeyNotFound {
 			return nil, err
 		}
 		if err == nil {
-			seriesID := bytesConvSeriesID(id)
-			s.l.Debug().
-				Hex("path", path.prefix).
-				Uint64("series_id", uint64(seriesID)).
-				Msg("got a series")
-			return []Series{newSeries(s.context(), seriesID, s)}, nil
+			records, errParse := unmarshalSeriesMetadata(val)
+			if errParse != nil {
+				return nil, errParse
+			}
+			return s.toSeries(path, records), nil
 		}
 		s.l.Debug().Hex("path", path.prefix).Msg("doesn't get any series")
 		return nil, nil
@@ -171,17 +297,17 @@ func (s *seriesDB) List(path Path) (SeriesList, error) {
 			comparableKey[i] = path.mask[i] & b
 		}
 		if bytes.Equal(path.template, comparableKey) {
-			id, errGetVal := getVal()
+			val, errGetVal := getVal()
 			if errGetVal != nil {
 				err = multierr.Append(err, errGetVal)
 				return nil
 			}
-			seriesID := bytesConvSeriesID(id)
-			s.l.Debug().
-				Hex("path", path.prefix).
-				Uint64("series_id", uint64(seriesID)).
-				Msg("got a series")
-			result = append(result, newSeries(s.context(), seriesID, s))
+			records, errParse := unmarshalSeriesMetadata(val)
+			if errParse != nil {
+				err = multierr.Append(err, errParse)
+				return nil
+			}
+			result = append(result, s.toSeries(path, records)...)
 		}
 		return nil
 	})
@@ -191,30 +317,128 @@ func (s *seriesDB) List(path Path) (SeriesList, error) {
 	return result, err
 }
 
-func (s *seriesDB) span(_ TimeRange) []blockDelegate {
-	//TODO: return correct blocks
-	result := make([]blockDelegate, 0, len(s.lst[0].lst))
-	for _, b := range s.lst[0].lst {
-		result = append(result, b.delegate())
+// all returns the records of all series
+func (s *seriesDB) all() ([]seriesRecord, error) {
+	var result []seriesRecord
+	err := s.seriesMetadata.Scan(nil, kv.DefaultScanOpts, func(_ int, _ []byte, getVal func() ([]byte, error)) error {
+		val, err := getVal()
+		if err != nil {
+			return err
+		}
+		records, err := unmarshalSeriesMetadata(val)
+		if err != nil {
+			return err
+		}
+		result = append(result, records...)
+		return nil
+	})
+	return result, err
+}
+
+func (s *seriesDB) toSeries(path Path, records []seriesRecord) []Series {
+	result := make([]Series, 0, len(records))
+	for _, r := range records {
+		if !path.match(r.entity) {
+			continue
+		}
+		s.l.Debug().
+			Hex("path", path.prefix).
+			Uint64("series_id", uint64(r.id)).
+			Msg("got a series")
+		result = append(result, newSeries(s.context(), r.id, r.entity, s))
 	}
 	return result
 }
 
+func (s *seriesDB) Delete(entity Entity) error {
+	records, err := s.records(HashEntity(entity))
+	if err != nil {
+		return err
+	}
+	r, ok := matchRecord(records, entity)
+	if !ok {
+		return nil
+	}
+	seriesID := r.id
+	// the items written ahead of now are deleted as well
+	end := time.Now()
+	segments := s.segCtrl.segments()
+	for _, seg := range segments {
+		for _, b := range seg.blocks() {
+			latest, found, errLatest := b.latestItemTime(seriesID)
+			if errLatest != nil {
+				return errors.WithMessagef(errLatest, "failed to look up the latest item of the series %d", seriesID)
+			}
+			if found && !latest.Before(end) {
+				end = latest.Add(time.Nanosecond)
+			}
+		}
+	}
+	for _, seg := range segments {
+		if !seg.startTime.Before(end) {
+			continue
+		}
+		timeRange := NewTimeRange(seg.startTime, end)
+		for _, b := range seg.blocks() {
+			d := b.delegate()
+			err = multierr.Combine(err, d.delete(seriesID, timeRange), d.Close())
+		}
+	}
+	if err != nil {
+		return errors.WithMessagef(err, "failed to delete the series %d", seriesID)
+	}
+	s.l.Info().Uint64("series_id", uint64(seriesID)).Msg("deleted the series")
+	return nil
+}
+
+func (s *seriesDB) span(timeRange TimeRange) []blockDelegate {
+	result := make([]blockDelegate, 0)
+	for _, seg := range s.segCtrl.segments() {
+		if !seg.overlapping(timeRange) {
+			continue
+		}
+		for _, b := range seg.blocks() {
+			if b.overlapping(timeRange) {
+				result = append(result, b.delegate())
+			}
+		}
+	}
+	return result
+}
+
+func (s *seriesDB) create(ts time.Time) (blockDelegate, error) {
+	b, err := s.segCtrl.blockFor(ts)
+	if err != nil {
+		return nil, err
+	}
+	return b.delegate(), nil
+}
+
 func (s *seriesDB) context() context.Context {
 	return context.WithValue(context.Background(), logger.ContextKey, s.l)
 }
 
+func (s *seriesDB) snapshot(dir string) error {
+	return multierr.Combine(
+		s.seriesMetadata.Snapshot(dir+"/md"),
+		s.seriesIDs.Snapshot(dir+"/id"),
+	)
+}
+
+// stats returns the number of the series and the disk size of the series database
+func (s *seriesDB) stats() (seriesCount uint64, diskSize int64) {
+	idStats := s.seriesIDs.Stats()
+	return idStats.KeyCount, idStats.DiskSize + s.seriesMetadata.Stats().DiskSize
+}
+
 func (s *seriesDB) Close() error {
-	for _, seg := range s.lst {
-		seg.close()
-	}
-	return s.seriesMetadata.Close()
+	return multierr.Combine(s.seriesMetadata.Close(), s.seriesIDs.Close())
 }
 
-func newSeriesDataBase(ctx context.Context, shardID common.ShardID, path string, segLst []*segment) (SeriesDatabase, error) {
+func newSeriesDataBase(ctx context.Context, shardID common.ShardID, path string, segCtrl *segmentController) (SeriesDatabase, error) {
 	sdb := &seriesDB{
-		sID: shardID,
-		lst: segLst,
+		sID:     shardID,
+		segCtrl: segCtrl,
 	}
 	parentLogger := ctx.Value(logger.ContextKey)
 	if parentLogger == nil {
@@ -223,11 +447,20 @@ func newSeriesDataBase(ctx context.Context, shardID common.ShardID, path string,
 	if pl, ok := parentLogger.(*logger.Logger); ok {
 		sdb.l = pl.Named("series_database")
 	}
+	var storeOpts []kv.StoreOptions
+	if inMemory, _ := ctx.Value(inMemoryKey).(bool); inMemory {
+		storeOpts = append(storeOpts, kv.StoreWithInMemory())
+	}
 	var err error
-	sdb.seriesMetadata, err = kv.OpenStore(0, path+"/md", kv.StoreWithNamedLogger("metadata", sdb.l))
+	sdb.seriesMetadata, err = kv.OpenStore(0, path+"/md", append(storeOpts, kv.StoreWithNamedLogger("metadata", sdb.l))...)
 	if err != nil {
 		return nil, err
 	}
+	sdb.seriesIDs, err = kv.OpenStore(0, path+"/id", append(storeOpts, kv.StoreWithNamedLogger("series_id", sdb.l))...)
+	if err != nil {
+		_ = sdb.seriesMetadata.Close()
+		return nil, err
+	}
 	return sdb, nil
 }
 
@@ -247,6 +480,88 @@ func bytesConvSeriesID(data []byte) common.SeriesID {
 	return common.SeriesID(convert.BytesToUint64(data))
 }
 
+type seriesRecord struct {
+	id     common.SeriesID
+	entity Entity
+}
+
+// matchRecord finds the record of the entity. A record without the entity is matched if it's the only one,
+// so is the only record by a nil entity.
+func matchRecord(records []seriesRecord, entity Entity) (seriesRecord, bool) {
+	if len(records) < 1 {
+		return seriesRecord{}, false
+	}
+	if entity == nil {
+		return records[0], len(records) == 1
+	}
+	for _, r := range records {
+		if r.entity.Equal(entity) {
+			return r, true
+		}
+	}
+	if len(records) == 1 && records[0].entity == nil {
+		return records[0], true
+	}
+	return seriesRecord{}, false
+}
+
+// marshalSeriesMetadata encodes the records, each of which is the series id,
+// the number of entries and the length-prefixed entries of the entity
+func marshalSeriesMetadata(records []seriesRecord) []byte {
+	result := make([]byte, 0, len(records)*16)
+	for _, r := range records {
+		result = append(result, r.id.Marshal()...)
+		result = append(result, convert.Uint32ToBytes(uint32(len(r.entity)))...)
+		for _, entry := range r.entity {
+			result = append(result, convert.Uint32ToBytes(uint32(len(entry)))...)
+			result = append(result, entry...)
+		}
+	}
+	return result
+}
+
+// unmarshalSeriesMetadata decodes the value of the series metadata.
+// A value of a sole series id is a series created without the entity.
+func unmarshalSeriesMetadata(data []byte) ([]seriesRecord, error) {
+	if len(data) == 8 {
+		return []seriesRecord{{id: bytesConvSeriesID(data)}}, nil
+	}
+	var records []seriesRecord
+	var offset int
+	next := func(size int) ([]byte, error) {
+		if offset+size > len(data) {
+			return nil, errors.Wrapf(ErrSeriesMetadataMalformed, "value: %x", data)
+		}
+		b := data[offset : offset+size]
+		offset += size
+		return b, nil
+	}
+	for offset < len(data) {
+		id, err := next(8)
+		if err != nil {
+			return nil, err
+		}
+		num, err := next(4)
+		if err != nil {
+			return nil, err
+		}
+		r := seriesRecord{id: bytesConvSeriesID(id)}
+		for i := uint32(0); i < convert.BytesToUint32(num); i++ {
+			size, errSize := next(4)
+			if errSize != nil {
+				return nil, errSize
+			}
+			entry, errEntry := next(int(convert.BytesToUint32(size)))
+			if errEntry != nil {
+				return nil, errEntry
+			}
+			r.entity = append(r.entity, append(Entry{}, entry...))
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
 type SeriesList []Series
 
 func (a SeriesList) Len() int {
@@ -20,8 +20,11 @@ package tsdb
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"os"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -137,6 +140,7 @@ func TestNewPath(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got := NewPath(tt.entity)
+			tt.want.entries = tt.entity
 			tester.Equal(tt.want, got)
 		})
 	}
@@ -191,6 +195,96 @@ func Test_SeriesDatabase_Get(t *testing.T) {
 	}
 }
 
+func Test_SeriesDatabase_Span(t *testing.T) {
+	req := require.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	dir, deferFunc := test.Space(req)
+	defer deferFunc()
+	shardPath := fmt.Sprintf(shardTemplate, dir, 0)
+	for _, p := range []string{
+		fmt.Sprintf(blockTemplate, fmt.Sprintf(segTemplate, shardPath, "20220101"), "0000"),
+		fmt.Sprintf(blockTemplate, fmt.Sprintf(segTemplate, shardPath, "20220101"), "1200"),
+		fmt.Sprintf(blockTemplate, fmt.Sprintf(segTemplate, shardPath, "20220102"), "0000"),
+	} {
+		req.NoError(os.MkdirAll(p, dirPerm))
+	}
+	db := openDatabase(req, dir)
+	defer db.Close()
+	shard, err := db.Shard(0)
+	req.NoError(err)
+	s, err := shard.Series().Get(Entity{Entry("productpage"), Entry("10.0.0.1")})
+	req.NoError(err)
+	day := time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local)
+	seg0, seg1 := segIDFromTime(day), segIDFromTime(day.AddDate(0, 0, 1))
+	block1 := blockIDFromTime(day.Add(12 * time.Hour))
+	tests := []struct {
+		name      string
+		timeRange TimeRange
+		want      [][2]uint16
+		wantErr   error
+	}{
+		{
+			name:      "a point",
+			timeRange: NewTimeRangeDuration(day.Add(time.Hour), 0),
+			want:      [][2]uint16{{seg0, 0}},
+		},
+		{
+			name:      "the start of a block",
+			timeRange: NewTimeRangeDuration(day.Add(12*time.Hour), 0),
+			want:      [][2]uint16{{seg0, block1}},
+		},
+		{
+			name:      "across blocks",
+			timeRange: NewTimeRangeDuration(day.Add(11*time.Hour), 2*time.Hour),
+			want:      [][2]uint16{{seg0, 0}, {seg0, block1}},
+		},
+		{
+			name:      "end at the start of a block",
+			timeRange: NewTimeRangeDuration(day.Add(11*time.Hour), time.Hour),
+			want:      [][2]uint16{{seg0, 0}},
+		},
+		{
+			name:      "across segments",
+			timeRange: NewTimeRangeDuration(day.Add(23*time.Hour), 2*time.Hour),
+			want:      [][2]uint16{{seg0, block1}, {seg1, 0}},
+		},
+		{
+			name:      "the latest block",
+			timeRange: NewTimeRangeDuration(day.AddDate(0, 1, 0), time.Hour),
+			want:      [][2]uint16{{seg1, 0}},
+		},
+		{
+			name:      "before all blocks",
+			timeRange: NewTimeRangeDuration(day.Add(-2*time.Hour), time.Hour),
+			wantErr:   ErrEmptySeriesSpan,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := assert.New(t)
+			blocks := s.(*series).blockDB.span(tt.timeRange)
+			got := make([][2]uint16, 0, len(blocks))
+			for _, b := range blocks {
+				segID, blockID := b.identity()
+				got = append(got, [2]uint16{segID, blockID})
+				tester.NoError(b.Close())
+			}
+			span, err := s.Span(tt.timeRange)
+			if tt.wantErr != nil {
+				tester.ErrorIs(err, tt.wantErr)
+				tester.Empty(got)
+				return
+			}
+			tester.NoError(err)
+			tester.NoError(span.Close())
+			tester.Equal(tt.want, got)
+		})
+	}
+}
+
 func Test_SeriesDatabase_List(t *testing.T) {
 	tester := assert.New(t)
 	tester.NoError(logger.Init(logger.Logging{
@@ -202,6 +296,10 @@ func Test_SeriesDatabase_List(t *testing.T) {
 	s, err := newSeriesDataBase(context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")), 0, dir, nil)
 	tester.NoError(err)
 	data := setUpEntities(tester, s)
+	entities := make(map[common.SeriesID]Entity, len(data))
+	for _, d := range data {
+		entities[d.id] = d.entity
+	}
 	tests := []struct {
 		name    string
 		path    Path
@@ -281,10 +379,66 @@ func Test_SeriesDatabase_List(t *testing.T) {
 			}
 			tester.NoError(err)
 			tester.Equal(transform(tt.want), transform(series))
+			for _, got := range series {
+				tester.Equal(entities[got.ID()], got.Entity())
+			}
 		})
 	}
 }
 
+func Test_SeriesDatabase_Collision(t *testing.T) {
+	tester := assert.New(t)
+	req := require.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	dir, deferFunc := test.Space(req)
+	defer deferFunc()
+	s, err := newSeriesDataBase(context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")), 0, dir, nil)
+	req.NoError(err)
+	defer s.Close()
+	sdb := s.(*seriesDB)
+
+	// two entities share a hash key
+	key := HashEntity(Entity{Entry("productpage"), Entry("10.0.0.1")})
+	e1 := Entity{Entry("productpage"), Entry("10.0.0.1")}
+	e2 := Entity{Entry("reviews"), Entry("10.0.0.2")}
+	s1, err := sdb.getOrCreate(key, e1)
+	req.NoError(err)
+	s2, err := sdb.getOrCreate(key, e2)
+	req.NoError(err)
+	tester.NotEqual(s1.ID(), s2.ID())
+	tester.Equal(e2, s2.Entity())
+	got, err := sdb.getOrCreate(key, e1)
+	req.NoError(err)
+	tester.Equal(s1.ID(), got.ID())
+	tester.Equal(e1, got.Entity())
+	got, err = sdb.getOrCreate(key, e2)
+	req.NoError(err)
+	tester.Equal(s2.ID(), got.ID())
+	list, err := s.List(NewPath([]Entry{Entry("productpage"), Entry("10.0.0.1")}))
+	req.NoError(err)
+	tester.Equal(transform(SeriesList{s1}), transform(list), "the series sharing the hash key should be filtered out")
+	list, err = s.List(NewPath([]Entry{Entry("reviews"), AnyEntry}))
+	req.NoError(err)
+	tester.Empty(list, "the path doesn't match the hash keys")
+	_, err = s.GetByHashKey(key)
+	tester.ErrorIs(err, ErrSeriesAmbiguous)
+
+	// the series id of an entity is taken by another one
+	e3 := Entity{Entry("payment"), Entry("10.0.0.3")}
+	key3 := HashEntity(e3)
+	req.NoError(sdb.seriesIDs.Put(hash(key3), []byte("another key")))
+	s3, err := s.Get(e3)
+	req.NoError(err)
+	tester.NotEqual(bytesConvSeriesID(hash(key3)), s3.ID())
+	got, err = s.Get(e3)
+	req.NoError(err)
+	tester.Equal(s3.ID(), got.ID())
+	tester.Equal(e3, got.Entity())
+}
+
 type entityWithID struct {
 	id     common.SeriesID
 	entity Entity
@@ -338,7 +492,7 @@ func setUpEntities(t *assert.Assertions, db SeriesDatabase) []*entityWithID {
 }
 
 func newMockSeries(id common.SeriesID, blockDB *seriesDB) *series {
-	return newSeries(context.TODO(), id, blockDB)
+	return newSeries(context.TODO(), id, nil, blockDB)
 }
 
 func transform(list SeriesList) (seriesIDs []common.SeriesID) {
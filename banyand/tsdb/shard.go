@@ -19,8 +19,9 @@ package tsdb
 
 import (
 	"context"
-	"sync"
-	"time"
+	"path/filepath"
+
+	"github.com/dgraph-io/ristretto/z"
 
 	"github.com/apache/skywalking-banyandb/api/common"
 )
@@ -28,13 +29,13 @@ import (
 var _ Shard = (*shard)(nil)
 
 type shard struct {
-	sync.Mutex
 	id common.ShardID
 
-	location       string
-	seriesDatabase SeriesDatabase
-	indexDatabase  IndexDatabase
-	lst            []*segment
+	location          string
+	seriesDatabase    SeriesDatabase
+	indexDatabase     IndexDatabase
+	segmentController *segmentController
+	rotationCloser    *z.Closer
 }
 
 func (s *shard) ID() common.ShardID {
@@ -49,41 +50,77 @@ func (s *shard) Index() IndexDatabase {
 	return s.indexDatabase
 }
 
-func newShard(ctx context.Context, id common.ShardID, location string) (*shard, error) {
+type shardOpts struct {
+	segmentControllerOpts
+	id common.ShardID
+}
+
+func openShard(ctx context.Context, opts shardOpts) (*shard, error) {
 	s := &shard{
-		id:       id,
-		location: location,
+		id:                opts.id,
+		location:          opts.location,
+		segmentController: newSegmentController(ctx, opts.segmentControllerOpts),
 	}
-	segPath, err := mkdir(segTemplate, location, time.Now().Format(segFormat))
-	if err != nil {
+	if err := s.segmentController.open(); err != nil {
 		return nil, err
 	}
-	seg, err := newSegment(ctx, segPath)
-	if err != nil {
-		return nil, err
-	}
-	{
-		s.Lock()
-		defer s.Unlock()
-		s.lst = append(s.lst, seg)
-	}
-	seriesPath, err := mkdir(seriesTemplate, s.location)
+	seriesPath, err := mkdirUnlessInMemory(ctx, seriesTemplate, s.location)
 	if err != nil {
 		return nil, err
 	}
-	sdb, err := newSeriesDataBase(ctx, s.id, seriesPath, s.lst)
+	sdb, err := newSeriesDataBase(ctx, s.id, seriesPath, s.segmentController)
 	if err != nil {
 		return nil, err
 	}
 	s.seriesDatabase = sdb
-	idb, err := newIndexDatabase(ctx, s.id, s.lst)
+	idb, err := newIndexDatabase(ctx, s.id, s.segmentController)
 	if err != nil {
 		return nil, err
 	}
 	s.indexDatabase = idb
+	s.rotationCloser = z.NewCloser(1)
+	go s.segmentController.rotate(s.rotationCloser)
 	return s, nil
 }
 
+// Snapshot copies the segments ahead of the series database, which covers all series of the copied items
+func (s *shard) Snapshot(dir string) error {
+	for _, seg := range s.segmentController.segments() {
+		segDir, err := mkdir(dirTemplate, dir, filepath.Base(seg.path))
+		if err != nil {
+			return err
+		}
+		if err = seg.snapshot(segDir); err != nil {
+			return err
+		}
+	}
+	seriesDir, err := mkdir(seriesTemplate, dir)
+	if err != nil {
+		return err
+	}
+	return s.seriesDatabase.(*seriesDB).snapshot(seriesDir)
+}
+
+func (s *shard) Stats() ShardStats {
+	result := ShardStats{
+		ID: s.id,
+	}
+	result.SeriesCount, result.DiskSize = s.seriesDatabase.(*seriesDB).stats()
+	for _, seg := range s.segmentController.segments() {
+		segStats := seg.stats()
+		result.DiskSize += segStats.DiskSize
+		result.ItemCount += segStats.ItemCount
+		result.IndexTermCount += segStats.GlobalIndexTermCount
+		for _, bs := range segStats.Blocks {
+			result.IndexTermCount += bs.IndexTermCount
+		}
+		result.Segments = append(result.Segments, segStats)
+	}
+	return result
+}
+
 func (s *shard) Close() error {
+	s.rotationCloser.SignalAndWait()
+	s.segmentController.close()
 	return s.seriesDatabase.Close()
 }
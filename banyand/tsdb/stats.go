@@ -0,0 +1,139 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	"github.com/apache/skywalking-banyandb/pkg/bus"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+)
+
+// ShardStats is the statistics of a shard, which sums up the ones of its segments
+type ShardStats struct {
+	ID common.ShardID
+	// DiskSize includes the series database and all segments
+	DiskSize    int64
+	SeriesCount uint64
+	ItemCount   uint64
+	// IndexTermCount includes the terms of the segments' global indices and the blocks' indices
+	IndexTermCount uint64
+	Segments       []SegmentStats
+}
+
+type SegmentStats struct {
+	ID        uint16
+	StartTime time.Time
+	// EndTime is zero if the segment is not sealed
+	EndTime              time.Time
+	DiskSize             int64
+	ItemCount            uint64
+	GlobalIndexTermCount uint64
+	Blocks               []BlockStats
+}
+
+type BlockStats struct {
+	ID        uint16
+	StartTime time.Time
+	// EndTime is zero if the block is the latest one
+	EndTime   time.Time
+	DiskSize  int64
+	ItemCount uint64
+	// IndexTermCount is the number of the terms in the inverted and lsm indices
+	IndexTermCount uint64
+}
+
+// ToProto converts the statistics of the shard of the stream or measure identified by the metadata
+func (s ShardStats) ToProto(metadata *commonv1.Metadata) *databasev1.ShardStats {
+	result := &databasev1.ShardStats{
+		Id:             uint64(s.ID),
+		Metadata:       metadata,
+		DiskSize:       s.DiskSize,
+		SeriesCount:    s.SeriesCount,
+		ItemCount:      s.ItemCount,
+		IndexTermCount: s.IndexTermCount,
+	}
+	for _, seg := range s.Segments {
+		segStats := &databasev1.SegmentStats{
+			Id:                   uint32(seg.ID),
+			StartTime:            timestamppb.New(seg.StartTime),
+			EndTime:              toTimestamp(seg.EndTime),
+			DiskSize:             seg.DiskSize,
+			ItemCount:            seg.ItemCount,
+			GlobalIndexTermCount: seg.GlobalIndexTermCount,
+		}
+		for _, b := range seg.Blocks {
+			segStats.Blocks = append(segStats.Blocks, &databasev1.BlockStats{
+				Id:             uint32(b.ID),
+				StartTime:      timestamppb.New(b.StartTime),
+				EndTime:        toTimestamp(b.EndTime),
+				DiskSize:       b.DiskSize,
+				ItemCount:      b.ItemCount,
+				IndexTermCount: b.IndexTermCount,
+			})
+		}
+		result.Segments = append(result.Segments, segStats)
+	}
+	return result
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// StatsCallback responds the statistics of the shards of a stream or measure.
+// The response carries an error instead if the resource doesn't exist.
+type StatsCallback struct {
+	l *logger.Logger
+	// lookup returns the database of the resource identified by the metadata
+	lookup func(metadata *commonv1.Metadata) (Database, error)
+}
+
+func NewStatsCallback(l *logger.Logger, lookup func(metadata *commonv1.Metadata) (Database, error)) *StatsCallback {
+	return &StatsCallback{
+		l:      l,
+		lookup: lookup,
+	}
+}
+
+func (s *StatsCallback) Rev(message bus.Message) (resp bus.Message) {
+	req, ok := message.Data().(*databasev1.AdminServiceStatsRequest)
+	if !ok {
+		s.l.Warn().Msg("invalid event data type")
+		return
+	}
+	meta := req.GetMetadata()
+	db, err := s.lookup(meta)
+	if err != nil {
+		return bus.NewMessage(message.ID(), err)
+	}
+	shards := db.Shards()
+	result := make([]*databasev1.ShardStats, 0, len(shards))
+	for _, shard := range shards {
+		result = append(result, shard.Stats().ToProto(meta))
+	}
+	return bus.NewMessage(message.ID(), result)
+}
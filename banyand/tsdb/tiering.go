@@ -0,0 +1,76 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"time"
+
+	"github.com/dgraph-io/ristretto/z"
+
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+)
+
+// tieringController moves the segments ending before the age to the cold location periodically
+type tieringController struct {
+	shards   []*shard
+	age      time.Duration
+	interval time.Duration
+	closer   *z.Closer
+	l        *logger.Logger
+}
+
+func newTieringController(shards []*shard, age, interval time.Duration, l *logger.Logger) *tieringController {
+	return &tieringController{
+		shards:   shards,
+		age:      age,
+		interval: interval,
+		closer:   z.NewCloser(1),
+		l:        l.Named("tiering"),
+	}
+}
+
+func (tc *tieringController) start() {
+	go func() {
+		defer tc.closer.Done()
+		ticker := time.NewTicker(tc.interval)
+		defer ticker.Stop()
+		tc.l.Info().Dur("age", tc.age).Dur("interval", tc.interval).Msg("started")
+		for {
+			select {
+			case <-tc.closer.HasBeenClosed():
+				return
+			case now := <-ticker.C:
+				tc.moveAged(now)
+			}
+		}
+	}()
+}
+
+func (tc *tieringController) moveAged(now time.Time) {
+	deadline := now.Add(-tc.age)
+	tc.l.Debug().Time("deadline", deadline).Msg("move aged segments")
+	for _, s := range tc.shards {
+		if err := s.segmentController.tier(deadline); err != nil {
+			tc.l.Error().Err(err).Uint("shard_id", uint(s.id)).Msg("failed to move aged segments")
+		}
+	}
+}
+
+func (tc *tieringController) stop() {
+	tc.closer.SignalAndWait()
+}
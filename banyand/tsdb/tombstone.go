@@ -0,0 +1,128 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	"github.com/apache/skywalking-banyandb/banyand/kv"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+)
+
+var ErrTombstoneMalformed = errors.New("serialized tombstone is malformed")
+
+// tombstone records the deleted time ranges of series in a segment.
+// The deleted data is removed physically along with the segment when it's expired.
+type tombstone struct {
+	sync.RWMutex
+	store  kv.Store
+	ranges map[common.SeriesID][]TimeRange
+}
+
+func openTombstone(path string, l *logger.Logger, options ...kv.StoreOptions) (*tombstone, error) {
+	t := &tombstone{
+		ranges: make(map[common.SeriesID][]TimeRange),
+	}
+	var err error
+	if t.store, err = kv.OpenStore(0, path, append(options, kv.StoreWithNamedLogger("tombstone", l))...); err != nil {
+		return nil, err
+	}
+	err = t.store.Scan(nil, kv.DefaultScanOpts, func(_ int, key []byte, _ func() ([]byte, error)) error {
+		seriesID, timeRange, errParse := parseTombstoneKey(key)
+		if errParse != nil {
+			return errParse
+		}
+		t.ranges[seriesID] = append(t.ranges[seriesID], timeRange)
+		return nil
+	})
+	if err != nil {
+		_ = t.store.Close()
+		return nil, errors.WithMessagef(err, "failed to load tombstones from %s", path)
+	}
+	return t, nil
+}
+
+func (t *tombstone) add(seriesID common.SeriesID, timeRange TimeRange) error {
+	t.Lock()
+	defer t.Unlock()
+	for _, r := range t.ranges[seriesID] {
+		if r.Start.Equal(timeRange.Start) && r.End.Equal(timeRange.End) {
+			return nil
+		}
+	}
+	if err := t.store.Put(tombstoneKey(seriesID, timeRange), convert.Int64ToBytes(time.Now().UnixNano())); err != nil {
+		return err
+	}
+	t.ranges[seriesID] = append(t.ranges[seriesID], timeRange)
+	return nil
+}
+
+func (t *tombstone) get(seriesID common.SeriesID) []TimeRange {
+	t.RLock()
+	defer t.RUnlock()
+	ranges := t.ranges[seriesID]
+	result := make([]TimeRange, len(ranges))
+	copy(result, ranges)
+	return result
+}
+
+func (t *tombstone) deleted(seriesID common.SeriesID, unixNano uint64) bool {
+	t.RLock()
+	defer t.RUnlock()
+	return deleted(t.ranges[seriesID], unixNano)
+}
+
+func (t *tombstone) snapshot(dir string) error {
+	return t.store.Snapshot(dir)
+}
+
+func (t *tombstone) close() error {
+	return t.store.Close()
+}
+
+func deleted(ranges []TimeRange, unixNano uint64) bool {
+	for _, r := range ranges {
+		if r.contains(unixNano) {
+			return true
+		}
+	}
+	return false
+}
+
+func tombstoneKey(seriesID common.SeriesID, timeRange TimeRange) []byte {
+	return bytes.Join([][]byte{
+		seriesID.Marshal(),
+		convert.Int64ToBytes(timeRange.Start.UnixNano()),
+		convert.Int64ToBytes(timeRange.End.UnixNano()),
+	}, nil)
+}
+
+func parseTombstoneKey(key []byte) (common.SeriesID, TimeRange, error) {
+	if len(key) != 8+8+8 {
+		return 0, TimeRange{}, errors.Wrapf(ErrTombstoneMalformed, "key: %x", key)
+	}
+	return common.SeriesID(convert.BytesToUint64(key[:8])),
+		NewTimeRange(time.Unix(0, convert.BytesToInt64(key[8:16])), time.Unix(0, convert.BytesToInt64(key[16:]))),
+		nil
+}
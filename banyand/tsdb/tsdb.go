@@ -24,7 +24,11 @@ import (
 	"io/fs"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
@@ -41,28 +45,47 @@ const (
 	segTemplate         = "%s/seg-%s"
 	blockTemplate       = "%s/block-%s"
 	globalIndexTemplate = "%s/index"
+	tombstoneTemplate   = "%s/tombstone"
+	dirTemplate         = "%s/%s"
+
+	shardPathPrefix = "shard-"
+	segPathPrefix   = "seg-"
+	blockPathPrefix = "block-"
 
 	segFormat   = "20060102"
 	blockFormat = "1504"
 
 	dirPerm = 0700
+
+	defaultBlockInterval = 2 * time.Hour
 )
 
 var (
 	ErrInvalidShardID       = errors.New("invalid shard id")
 	ErrEncodingMethodAbsent = errors.New("encoding method is absent")
+	ErrOutOfBackfillWindow  = errors.New("the time is out of the backfill window")
+	ErrLocationNotEmpty     = errors.New("the location is not empty")
+	ErrSegmentReadOnly      = errors.New("the segment is read-only")
+	ErrExpired              = errors.New("the time is beyond the ttl")
+	ErrLegacyLayout         = errors.New("the shards in the root path are shared by all the resources in the legacy layout")
 
-	indexRulesKey     = contextIndexRulesKey{}
-	encodingMethodKey = contextEncodingMethodKey{}
+	indexRulesKey      = contextIndexRulesKey{}
+	encodingMethodKey  = contextEncodingMethodKey{}
+	duplicatePolicyKey = contextDuplicatePolicyKey{}
+	inMemoryKey        = contextInMemoryKey{}
 )
 
 type contextIndexRulesKey struct{}
 type contextEncodingMethodKey struct{}
+type contextDuplicatePolicyKey struct{}
+type contextInMemoryKey struct{}
 
 type Database interface {
 	io.Closer
 	Shards() []Shard
 	Shard(id common.ShardID) (Shard, error)
+	// Snapshot writes a point-in-time copy of all shards into a directory, which could be restored by Restore
+	Snapshot(dir string) error
 }
 
 type Shard interface {
@@ -70,15 +93,33 @@ type Shard interface {
 	ID() common.ShardID
 	Series() SeriesDatabase
 	Index() IndexDatabase
+	Snapshot(dir string) error
+	// Stats collects the disk usage and the counts of the series, items and index terms
+	Stats() ShardStats
 }
 
 var _ Database = (*database)(nil)
 
 type DatabaseOpts struct {
-	Location       string
-	ShardNum       uint32
-	IndexRules     []*databasev1.IndexRule
-	EncodingMethod EncodingMethod
+	Location          string
+	ShardNum          uint32
+	IndexRules        []*databasev1.IndexRule
+	EncodingMethod    EncodingMethod
+	BlockInterval     time.Duration
+	TTL               CalendarInterval
+	RetentionInterval time.Duration
+	// BackfillWindow limits how far in the past the data could be written, zero means no limit
+	BackfillWindow time.Duration
+	// DuplicatePolicy indicates how to handle the items sharing a series and a timestamp
+	DuplicatePolicy databasev1.DuplicatePolicy
+	// ColdLocation is the secondary location of the segments ending ColdAge ago, empty disables the tiering
+	ColdLocation string
+	ColdAge      time.Duration
+	// TieringInterval is the interval of moving the aged segments, zero disables the moving
+	TieringInterval time.Duration
+	// InMemory keeps the data in the memory, which is lost after closing. Nothing is created in the Location.
+	// It disables the tiering.
+	InMemory bool
 }
 
 type EncodingMethod struct {
@@ -87,11 +128,17 @@ type EncodingMethod struct {
 }
 
 type database struct {
-	logger   *logger.Logger
-	location string
-	shardNum uint32
+	logger         *logger.Logger
+	location       string
+	shardNum       uint32
+	coldLocation   string
+	blockInterval  time.Duration
+	backfillWindow time.Duration
+	ttl            CalendarInterval
 
-	sLst []Shard
+	sLst      []Shard
+	retention *retentionController
+	tiering   *tieringController
 	sync.Mutex
 }
 
@@ -107,16 +154,63 @@ func (d *database) Shard(id common.ShardID) (Shard, error) {
 }
 
 func (d *database) Close() error {
+	if d.retention != nil {
+		d.retention.stop()
+	}
+	if d.tiering != nil {
+		d.tiering.stop()
+	}
 	for _, s := range d.sLst {
 		_ = s.Close()
 	}
 	return nil
 }
 
+func (d *database) Snapshot(dir string) (err error) {
+	for _, s := range d.sLst {
+		shardDir, errInternal := mkdir(shardTemplate, dir, s.ID())
+		if errInternal != nil {
+			return errInternal
+		}
+		if errSnapshot := s.Snapshot(shardDir); errSnapshot != nil {
+			err = multierr.Append(err, errors.WithMessagef(errSnapshot, "failed to snapshot the shard %d", s.ID()))
+		}
+	}
+	if err == nil {
+		d.logger.Info().Str("dir", dir).Msg("took a snapshot")
+	}
+	return err
+}
+
+// Restore copies the snapshot into the location of opts, and opens the database from it
+func Restore(ctx context.Context, snapshotDir string, opts DatabaseOpts) (Database, error) {
+	entries, err := ioutil.ReadDir(opts.Location)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read %s", opts.Location)
+	}
+	if len(entries) > 0 {
+		return nil, errors.WithMessagef(ErrLocationNotEmpty, "failed to restore into %s", opts.Location)
+	}
+	if err = copyDir(snapshotDir, opts.Location); err != nil {
+		return nil, errors.WithMessagef(err, "failed to restore from %s", snapshotDir)
+	}
+	return OpenDatabase(ctx, opts)
+}
+
 func OpenDatabase(ctx context.Context, opts DatabaseOpts) (Database, error) {
 	db := &database{
-		location: opts.Location,
-		shardNum: opts.ShardNum,
+		location:       opts.Location,
+		coldLocation:   opts.ColdLocation,
+		shardNum:       opts.ShardNum,
+		blockInterval:  opts.BlockInterval,
+		backfillWindow: opts.BackfillWindow,
+		ttl:            opts.TTL,
+	}
+	if db.blockInterval <= 0 {
+		db.blockInterval = defaultBlockInterval
+	}
+	if opts.InMemory {
+		db.coldLocation = ""
 	}
 	parentLogger := ctx.Value(logger.ContextKey)
 	if parentLogger != nil {
@@ -127,47 +221,170 @@ func OpenDatabase(ctx context.Context, opts DatabaseOpts) (Database, error) {
 	if opts.EncodingMethod.EncoderPool == nil || opts.EncodingMethod.DecoderPool == nil {
 		return nil, errors.Wrap(ErrEncodingMethodAbsent, "failed to open database")
 	}
-	if _, err := mkdir(opts.Location); err != nil {
-		return nil, err
-	}
-	db.logger.Info().Str("path", opts.Location).Msg("initialized")
 	var entries []fs.FileInfo
 	var err error
-	if entries, err = ioutil.ReadDir(opts.Location); err != nil {
-		return nil, errors.Wrap(err, "failed to read directory contents failed")
+	if !opts.InMemory {
+		if _, err = mkdir(opts.Location); err != nil {
+			return nil, err
+		}
+		if entries, err = ioutil.ReadDir(opts.Location); err != nil {
+			return nil, errors.Wrap(err, "failed to read directory contents failed")
+		}
 	}
+	db.logger.Info().Str("path", opts.Location).Bool("in_memory", opts.InMemory).Msg("initialized")
 	thisContext := context.WithValue(ctx, logger.ContextKey, db.logger)
 	thisContext = context.WithValue(thisContext, indexRulesKey, opts.IndexRules)
 	thisContext = context.WithValue(thisContext, encodingMethodKey, opts.EncodingMethod)
+	thisContext = context.WithValue(thisContext, duplicatePolicyKey, opts.DuplicatePolicy)
+	thisContext = context.WithValue(thisContext, inMemoryKey, opts.InMemory)
 	if len(entries) > 0 {
-		return loadDatabase(thisContext, db)
+		err = loadDatabase(thisContext, db)
+	} else {
+		err = createDatabase(thisContext, db)
+	}
+	if err != nil {
+		return nil, err
+	}
+	shards := make([]*shard, 0, len(db.sLst))
+	for _, s := range db.sLst {
+		shards = append(shards, s.(*shard))
+	}
+	if opts.TTL.Num > 0 && opts.RetentionInterval > 0 {
+		db.retention = newRetentionController(shards, opts.TTL, opts.RetentionInterval, db.logger)
+		db.retention.start()
 	}
-	return createDatabase(thisContext, db)
+	if db.coldLocation != "" && opts.ColdAge > 0 && opts.TieringInterval > 0 {
+		db.tiering = newTieringController(shards, opts.ColdAge, opts.TieringInterval, db.logger)
+		db.tiering.start()
+	}
+	return db, nil
 }
 
-func createDatabase(ctx context.Context, db *database) (Database, error) {
+func createDatabase(ctx context.Context, db *database) error {
 	var err error
 	db.Lock()
 	defer db.Unlock()
 	for i := uint32(0); i < db.shardNum; i++ {
-		shardLocation, errInternal := mkdir(shardTemplate, db.location, i)
+		shardLocation, errInternal := mkdirUnlessInMemory(ctx, shardTemplate, db.location, i)
 		if errInternal != nil {
 			err = multierr.Append(err, errInternal)
 			continue
 		}
-		so, errNewShard := newShard(ctx, common.ShardID(i), shardLocation)
+		so, errNewShard := openShard(ctx, db.shardOpts(common.ShardID(i), shardLocation))
 		if errNewShard != nil {
 			err = multierr.Append(err, errNewShard)
 			continue
 		}
 		db.sLst = append(db.sLst, so)
 	}
-	return db, err
+	return err
 }
 
-func loadDatabase(ctx context.Context, db *database) (Database, error) {
-	//TODO: load the existing database
-	return db, nil
+func loadDatabase(ctx context.Context, db *database) error {
+	db.Lock()
+	defer db.Unlock()
+	db.sLst = make([]Shard, db.shardNum)
+	err := walkDir(db.location, shardPathPrefix, func(suffix, absolutePath string) error {
+		shardID, errParse := strconv.Atoi(suffix)
+		if errParse != nil {
+			return errors.Wrapf(errParse, "invalid shard id: %s", suffix)
+		}
+		if shardID >= int(db.shardNum) {
+			db.logger.Warn().Int("shard_id", shardID).Uint32("shard_num", db.shardNum).Msg("ignore the shard beyond the shard number")
+			return nil
+		}
+		so, errOpenShard := openShard(ctx, db.shardOpts(common.ShardID(shardID), absolutePath))
+		if errOpenShard != nil {
+			return errOpenShard
+		}
+		db.sLst[shardID] = so
+		db.logger.Info().Int("shard_id", shardID).Str("path", absolutePath).Msg("loaded a shard")
+		return nil
+	})
+	for i, s := range db.sLst {
+		if s != nil {
+			continue
+		}
+		shardLocation, errInternal := mkdirUnlessInMemory(ctx, shardTemplate, db.location, i)
+		if errInternal != nil {
+			err = multierr.Append(err, errInternal)
+			continue
+		}
+		so, errNewShard := openShard(ctx, db.shardOpts(common.ShardID(i), shardLocation))
+		if errNewShard != nil {
+			err = multierr.Append(err, errNewShard)
+			continue
+		}
+		db.sLst[i] = so
+	}
+	if err != nil {
+		for _, s := range db.sLst {
+			if s != nil {
+				_ = s.Close()
+			}
+		}
+		return errors.WithMessagef(err, "failed to load the database from %s", db.location)
+	}
+	return nil
+}
+
+// shardOpts derives the options of a shard in the location from the database's.
+// The cold location of the shard is empty if the tiering is disabled.
+func (d *database) shardOpts(id common.ShardID, location string) shardOpts {
+	opts := shardOpts{
+		id: id,
+		segmentControllerOpts: segmentControllerOpts{
+			location:       location,
+			blockInterval:  d.blockInterval,
+			backfillWindow: d.backfillWindow,
+			ttl:            d.ttl,
+		},
+	}
+	if d.coldLocation != "" {
+		opts.coldLocation = fmt.Sprintf(shardTemplate, d.coldLocation, id)
+	}
+	return opts
+}
+
+type walkFn func(suffix, absolutePath string) error
+
+// walkDir visits the sub-directories whose name starts with the prefix in lexical order.
+// CheckLayout refuses the root path holding the shards directly, which are written by the legacy layout.
+// Those shards are shared by all the resources, which can't be told apart to be migrated,
+// so they have to be moved away before the server starts.
+func CheckLayout(root string) error {
+	shardNum, err := shardNumInLocation(root)
+	if err != nil {
+		return err
+	}
+	if shardNum > 0 {
+		return errors.WithMessagef(ErrLegacyLayout, "found %d shards in %s", shardNum, root)
+	}
+	return nil
+}
+
+func walkDir(root, prefix string, fn walkFn) error {
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return errors.Wrapf(err, "failed to walk the directory %s", root)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		if errWalk := fn(strings.TrimPrefix(entry.Name(), prefix), fmt.Sprintf(dirTemplate, root, entry.Name())); errWalk != nil {
+			return errWalk
+		}
+	}
+	return nil
+}
+
+// mkdirUnlessInMemory only formats the path if the data is kept in the memory, which leaves nothing on the disk
+func mkdirUnlessInMemory(ctx context.Context, format string, a ...interface{}) (string, error) {
+	if inMemory, _ := ctx.Value(inMemoryKey).(bool); inMemory {
+		return fmt.Sprintf(format, a...), nil
+	}
+	return mkdir(format, a...)
 }
 
 func mkdir(format string, a ...interface{}) (path string, err error) {
@@ -177,3 +394,37 @@ func mkdir(format string, a ...interface{}) (path string, err error) {
 	}
 	return path, err
 }
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, dirPerm)
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, out.Close())
+	}()
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -20,14 +20,23 @@ package tsdb
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/apache/skywalking-banyandb/api/common"
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	databasev1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/database/v1"
+	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/encoding"
+	"github.com/apache/skywalking-banyandb/pkg/index"
 	"github.com/apache/skywalking-banyandb/pkg/logger"
 	"github.com/apache/skywalking-banyandb/pkg/test"
 )
@@ -43,28 +52,1454 @@ func TestOpenDatabase(t *testing.T) {
 	now := time.Now()
 	segPath := fmt.Sprintf(segTemplate, shardPath, now.Format(segFormat))
 	validateDirectory(tester, segPath)
-	validateDirectory(tester, fmt.Sprintf(blockTemplate, segPath, now.Format(blockFormat)))
+	segStart := segmentStartTime(now)
+	blockStart := segStart.Add(now.Sub(segStart) / defaultBlockInterval * defaultBlockInterval)
+	validateDirectory(tester, fmt.Sprintf(blockTemplate, segPath, blockStart.Format(blockFormat)))
+}
+
+func TestCheckLayout(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	root, deferFunc := test.Space(req)
+	defer deferFunc()
+	req.NoError(os.MkdirAll(root+"/stream/data/default/sw/shard-0", 0o700))
+	tester.NoError(CheckLayout(root))
+	req.NoError(os.MkdirAll(root+"/shard-0", 0o700))
+	tester.ErrorIs(CheckLayout(root), ErrLegacyLayout)
+	tester.NoError(CheckLayout(root + "/absent"))
+}
+
+func TestWAL(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	root, deferFunc := test.Space(req)
+	defer deferFunc()
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	ctx := context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test"))
+	opts := WALOpts{
+		Root:     root,
+		Catalog:  "stream",
+		Metadata: &commonv1.Metadata{Group: "default", Name: "sw"},
+		ShardNum: 2,
+		Replay: func(data []byte, source []byte, cb func()) error {
+			return errors.New("nothing to replay")
+		},
+	}
+	w, err := OpenWAL(ctx, opts)
+	req.NoError(err)
+	req.NoError(w.Write(0, []byte("done"), func(source []byte, cb func()) error {
+		cb()
+		return nil
+	}))
+	// the entry of shard 1 is left as the data isn't written
+	req.NoError(w.Write(1, []byte("pending"), func(source []byte, cb func()) error {
+		return nil
+	}))
+	req.NoError(w.Close())
+
+	var replayed []string
+	opts.ShardNum = 1
+	opts.Replay = func(data []byte, source []byte, cb func()) error {
+		replayed = append(replayed, string(data))
+		cb()
+		return nil
+	}
+	w, err = OpenWAL(ctx, opts)
+	req.NoError(err)
+	defer w.Close()
+	tester.Equal([]string{"pending"}, replayed)
+	tester.DirExists(root + "/stream/wal/default/sw/shard-0")
+	tester.NoDirExists(root + "/stream/wal/default/sw/shard-1")
+	tester.ErrorIs(w.Write(1, []byte("invalid"), func(source []byte, cb func()) error {
+		return nil
+	}), ErrInvalidShardID)
+}
+
+func TestRotation(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	tempDir, deferFunc, db := setUp(req)
+	defer deferFunc()
+	defer db.Close()
+	s, err := db.Shard(0)
+	req.NoError(err)
+	segCtrl := s.(*shard).segmentController
+	now := time.Now()
+	segStart := segmentStartTime(now)
+	tester.Equal(segStart.Add(2*time.Hour), segCtrl.nextRotation(segStart.Add(time.Hour)))
+	tester.Equal(segStart.AddDate(0, 0, 1), segCtrl.nextRotation(segStart.Add(23*time.Hour)))
+
+	shardPath := fmt.Sprintf(shardTemplate, tempDir, 0)
+	segPath := fmt.Sprintf(segTemplate, shardPath, now.Format(segFormat))
+	nextBlock := segCtrl.nextRotation(now)
+	nextDay := segStart.AddDate(0, 0, 1)
+	req.NoError(segCtrl.rotateAt(nextBlock))
+	if !nextBlock.Equal(nextDay) {
+		validateDirectory(tester, fmt.Sprintf(blockTemplate, segPath, nextBlock.Format(blockFormat)))
+		blocks := segCtrl.segments()[0].blocks()
+		req.Len(blocks, 2)
+		tester.True(blocks[0].contains(now))
+		tester.False(blocks[0].contains(nextBlock))
+		tester.True(blocks[1].contains(nextBlock))
+		req.NoError(segCtrl.rotateAt(nextDay))
+	}
+	nextSegPath := fmt.Sprintf(segTemplate, shardPath, nextDay.Format(segFormat))
+	validateDirectory(tester, nextSegPath)
+	validateDirectory(tester, fmt.Sprintf(blockTemplate, nextSegPath, nextDay.Format(blockFormat)))
+	segments := segCtrl.segments()
+	req.Len(segments, 2)
+	tester.True(segments[0].contains(now))
+	tester.False(segments[0].contains(nextDay))
+	tester.True(segments[1].contains(nextDay))
+}
+
+func TestRetention(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	tempDir, deferFunc := test.Space(req)
+	defer deferFunc()
+	shardPath := fmt.Sprintf(shardTemplate, tempDir, 0)
+	expiredSegPath := fmt.Sprintf(segTemplate, shardPath, "20220101")
+	segPath := fmt.Sprintf(segTemplate, shardPath, "20220102")
+	req.NoError(os.MkdirAll(fmt.Sprintf(blockTemplate, expiredSegPath, "0000"), dirPerm))
+	req.NoError(os.MkdirAll(fmt.Sprintf(blockTemplate, segPath, "0000"), dirPerm))
+	db := openDatabase(req, tempDir)
+	defer db.Close()
+	s, err := db.Shard(0)
+	req.NoError(err)
+	segCtrl := s.(*shard).segmentController
+	req.Len(segCtrl.segments(), 2)
+
+	reader := s.Series().(*seriesDB).span(NewTimeRangeDuration(time.Date(2022, 1, 1, 1, 0, 0, 0, time.Local), 0))
+	req.Len(reader, 1)
+	expiredBlock := segCtrl.segments()[0].blocks()[0]
+	rc := newRetentionController([]*shard{s.(*shard)}, CalendarInterval{Unit: Day, Num: 1}, time.Hour, logger.GetLogger("test"))
+	done := make(chan struct{})
+	go func() {
+		rc.removeExpired(time.Date(2022, 1, 3, 1, 0, 0, 0, time.Local))
+		close(done)
+	}()
+	// the removal is waiting for the reader once the block is signaled to close
+	req.Eventually(func() bool {
+		select {
+		case <-expiredBlock.ref.HasBeenClosed():
+			return true
+		default:
+			return false
+		}
+	}, 10*time.Second, time.Millisecond)
+	select {
+	case <-done:
+		req.Fail("the segment is removed while being read")
+	default:
+	}
+	validateDirectory(tester, expiredSegPath)
+	req.NoError(reader[0].Close())
+	<-done
+	_, err = os.Stat(expiredSegPath)
+	tester.True(os.IsNotExist(err))
+	validateDirectory(tester, segPath)
+	segments := segCtrl.segments()
+	req.Len(segments, 1)
+	tester.Equal(segIDFromTime(time.Date(2022, 1, 2, 0, 0, 0, 0, time.Local)), segments[0].id)
+}
+
+func TestCalendarInterval(t *testing.T) {
+	tester := assert.New(t)
+	now := time.Date(2022, 3, 31, 12, 0, 0, 0, time.Local)
+	tester.Equal(time.Date(2022, 3, 31, 9, 0, 0, 0, time.Local), NewTTL(&databasev1.Duration{
+		Val:  3,
+		Unit: databasev1.Duration_DURATION_UNIT_HOUR,
+	}).PreviousTime(now))
+	tester.Equal(time.Date(2022, 3, 17, 12, 0, 0, 0, time.Local), NewTTL(&databasev1.Duration{
+		Val:  2,
+		Unit: databasev1.Duration_DURATION_UNIT_WEEK,
+	}).PreviousTime(now))
+	tester.Equal(time.Date(2022, 1, 31, 12, 0, 0, 0, time.Local), NewTTL(&databasev1.Duration{
+		Val:  2,
+		Unit: databasev1.Duration_DURATION_UNIT_MONTH,
+	}).PreviousTime(now))
+	tester.Zero(NewTTL(nil).Num)
+}
+
+func TestBackfill(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	tempDir, deferFunc := test.Space(req)
+	defer deferFunc()
+	open := func() Database {
+		db, err := OpenDatabase(
+			context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+			DatabaseOpts{
+				Location: tempDir,
+				ShardNum: 1,
+				EncodingMethod: EncodingMethod{
+					EncoderPool: encoding.NewPlainEncoderPool(0),
+					DecoderPool: encoding.NewPlainDecoderPool(0),
+				},
+				BackfillWindow: 7 * 24 * time.Hour,
+			})
+		req.NoError(err)
+		return db
+	}
+	db := open()
+	entity := Entity{Entry("productpage"), Entry("10.0.0.1")}
+	s, err := db.Shard(0)
+	req.NoError(err)
+	series, err := s.Series().Get(entity)
+	req.NoError(err)
+
+	_, err = series.Create(time.Now().AddDate(0, 0, -8))
+	tester.ErrorIs(err, ErrOutOfBackfillWindow)
+
+	ts := time.Now().AddDate(0, 0, -2)
+	span, err := series.Create(ts)
+	req.NoError(err)
+	writer, err := span.WriterBuilder().
+		Family([]byte("searchable"), []byte("v1")).
+		Time(ts).
+		Val([]byte("element-1")).
+		Build()
+	req.NoError(err)
+	itemID, err := writer.Write()
+	req.NoError(err)
+	req.NoError(span.Close())
+	shardPath := fmt.Sprintf(shardTemplate, tempDir, 0)
+	validateDirectory(tester, fmt.Sprintf(segTemplate, shardPath, ts.Format(segFormat)))
+	segments := s.(*shard).segmentController.segments()
+	req.Len(segments, 2)
+	tester.True(segments[0].contains(ts))
+	tester.False(segments[0].contains(time.Now()))
+
+	span, err = series.Span(NewTimeRangeDuration(ts, 0))
+	req.NoError(err)
+	req.NoError(span.Close())
+	req.NoError(db.Close())
+
+	db = open()
+	defer db.Close()
+	s, err = db.Shard(0)
+	req.NoError(err)
+	series, err = s.Series().Get(entity)
+	req.NoError(err)
+	item, closer, err := series.Get(itemID)
+	req.NoError(err)
+	defer closer.Close()
+	val, err := item.Val()
+	req.NoError(err)
+	tester.Equal([]byte("element-1"), val)
+
+	// the window is checked even if a segment contains the time
+	expired := time.Now().AddDate(0, 0, -8)
+	_, err = s.(*shard).segmentController.create(segmentStartTime(expired))
+	req.NoError(err)
+	_, err = series.Create(expired)
+	tester.ErrorIs(err, ErrOutOfBackfillWindow)
+}
+
+func TestExpired(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	tempDir, deferFunc := test.Space(req)
+	defer deferFunc()
+	db, err := OpenDatabase(
+		context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+		DatabaseOpts{
+			Location: tempDir,
+			ShardNum: 1,
+			EncodingMethod: EncodingMethod{
+				EncoderPool: encoding.NewPlainEncoderPool(0),
+				DecoderPool: encoding.NewPlainDecoderPool(0),
+			},
+			TTL: CalendarInterval{Unit: Day, Num: 3},
+		})
+	req.NoError(err)
+	defer db.Close()
+	s, err := db.Shard(0)
+	req.NoError(err)
+	series, err := s.Series().Get(Entity{Entry("productpage"), Entry("10.0.0.1")})
+	req.NoError(err)
+
+	expired := time.Now().AddDate(0, 0, -5)
+	_, err = series.Create(expired)
+	tester.ErrorIs(err, ErrExpired)
+	req.Len(s.(*shard).segmentController.segments(), 1)
+
+	_, err = s.(*shard).segmentController.create(segmentStartTime(expired))
+	req.NoError(err)
+	_, err = series.Create(expired)
+	tester.ErrorIs(err, ErrExpired)
+
+	span, err := series.Create(time.Now().AddDate(0, 0, -1))
+	req.NoError(err)
+	req.NoError(span.Close())
+}
+
+func TestTiering(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	tempDir, deferFunc := test.Space(req)
+	defer deferFunc()
+	coldDir, deferColdFunc := test.Space(req)
+	defer deferColdFunc()
+	open := func() Database {
+		db, err := OpenDatabase(
+			context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+			DatabaseOpts{
+				Location: tempDir,
+				ShardNum: 1,
+				EncodingMethod: EncodingMethod{
+					EncoderPool: encoding.NewPlainEncoderPool(0),
+					DecoderPool: encoding.NewPlainDecoderPool(0),
+				},
+				ColdLocation: coldDir,
+				ColdAge:      24 * time.Hour,
+			})
+		req.NoError(err)
+		return db
+	}
+	db := open()
+	entity := Entity{Entry("productpage"), Entry("10.0.0.1")}
+	s, err := db.Shard(0)
+	req.NoError(err)
+	series, err := s.Series().Get(entity)
+	req.NoError(err)
+	ts := time.Now().AddDate(0, 0, -2)
+	span, err := series.Create(ts)
+	req.NoError(err)
+	writer, err := span.WriterBuilder().
+		Family([]byte("searchable"), []byte("v1")).
+		Time(ts).
+		Val([]byte("element-1")).
+		Build()
+	req.NoError(err)
+	itemID, err := writer.Write()
+	req.NoError(err)
+	req.NoError(span.Close())
+
+	segCtrl := s.(*shard).segmentController
+	hotSeg := segCtrl.segments()[0]
+	exit, err := hotSeg.enterWrite()
+	req.NoError(err)
+	done := make(chan error)
+	go func() {
+		// the segment ends at the beginning of today
+		done <- segCtrl.tier(time.Now())
+	}()
+	select {
+	case <-done:
+		req.Fail("the moving should wait for the ongoing write")
+	case <-time.After(100 * time.Millisecond):
+	}
+	removed := make(chan error)
+	go func() {
+		// nothing expires, but the retention waits for the tiering
+		removed <- segCtrl.remove(ts.AddDate(0, 0, -1))
+	}()
+	select {
+	case <-removed:
+		req.Fail("the retention should wait for the tiering")
+	case <-time.After(100 * time.Millisecond):
+	}
+	exit()
+	select {
+	case err = <-done:
+		req.NoError(err)
+	case <-time.After(10 * time.Second):
+		req.Fail("the moving should be done once the write is done")
+	}
+	select {
+	case err = <-removed:
+		req.NoError(err)
+	case <-time.After(10 * time.Second):
+		req.Fail("the retention should be done once the tiering is done")
+	}
+	_, err = hotSeg.enterWrite()
+	tester.ErrorIs(err, ErrSegmentReadOnly, "the writes to the moved segment should be rejected")
+	hotSegPath := fmt.Sprintf(segTemplate, fmt.Sprintf(shardTemplate, tempDir, 0), ts.Format(segFormat))
+	coldSegPath := fmt.Sprintf(segTemplate, fmt.Sprintf(shardTemplate, coldDir, 0), ts.Format(segFormat))
+	_, err = os.Stat(hotSegPath)
+	tester.True(os.IsNotExist(err))
+	validateDirectory(tester, coldSegPath)
+	segments := segCtrl.segments()
+	req.Len(segments, 2)
+	tester.True(segments[0].isReadOnly())
+	tester.Equal(coldSegPath, segments[0].path)
+	tester.False(segments[1].isReadOnly())
+
+	verify := func(series Series) {
+		item, closer, errGet := series.Get(itemID)
+		req.NoError(errGet)
+		defer closer.Close()
+		val, errVal := item.Val()
+		req.NoError(errVal)
+		tester.Equal([]byte("element-1"), val)
+		span, errSpan := series.Span(NewTimeRangeDuration(ts, time.Hour))
+		req.NoError(errSpan)
+		defer span.Close()
+		seeker, errSeeker := span.SeekerBuilder().Build()
+		req.NoError(errSeeker)
+		iters, errSeek := seeker.Seek()
+		req.NoError(errSeek)
+		var found int
+		for _, iter := range iters {
+			for iter.Next() {
+				found++
+			}
+			req.NoError(iter.Close())
+		}
+		tester.Equal(1, found)
+	}
+	verify(series)
+	_, err = series.Create(ts)
+	tester.ErrorIs(err, ErrSegmentReadOnly)
+	req.NoError(db.Close())
+
+	db = open()
+	defer db.Close()
+	s, err = db.Shard(0)
+	req.NoError(err)
+	segments = s.(*shard).segmentController.segments()
+	req.Len(segments, 2)
+	tester.True(segments[0].isReadOnly())
+	series, err = s.Series().Get(entity)
+	req.NoError(err)
+	verify(series)
+}
+
+func TestVerify(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	tempDir, deferFunc := test.Space(req)
+	defer deferFunc()
+	quarantineDir, deferQuarantineFunc := test.Space(req)
+	defer deferQuarantineFunc()
+	db := openDatabase(req, tempDir)
+	s, err := db.Shard(0)
+	req.NoError(err)
+	series, err := s.Series().Get(Entity{Entry("productpage"), Entry("10.0.0.1")})
+	req.NoError(err)
+	ts := time.Now().AddDate(0, 0, -2)
+	span, err := series.Create(ts)
+	req.NoError(err)
+	writer, err := span.WriterBuilder().
+		Family([]byte("searchable"), []byte("v1")).
+		Time(ts).
+		Val([]byte("element-1")).
+		Build()
+	req.NoError(err)
+	_, err = writer.Write()
+	req.NoError(err)
+	req.NoError(span.Close())
+	req.NoError(db.Close())
+
+	segPath := fmt.Sprintf(segTemplate, fmt.Sprintf(shardTemplate, tempDir, 0), ts.Format(segFormat))
+	var blockPath string
+	req.NoError(walkDir(segPath, blockPathPrefix, func(_, absolutePath string) error {
+		blockPath = absolutePath
+		return nil
+	}))
+	statuses := func(results []VerifyResult) map[string]VerifyStatus {
+		m := make(map[string]VerifyStatus, len(results))
+		for _, r := range results {
+			m[r.Path] = r.Status
+		}
+		return m
+	}
+	results, err := Verify(tempDir, "")
+	req.NoError(err)
+	// the latest segment and its block aren't sealed
+	req.Len(results, 4)
+	tester.Equal(VerifyStatusOK, statuses(results)[segPath])
+	tester.Equal(VerifyStatusOK, statuses(results)[blockPath])
+
+	var damaged string
+	req.NoError(filepath.Walk(blockPath+"/store", func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && info.Size() > 0 && damaged == "" {
+			damaged = path
+		}
+		return err
+	}))
+	req.NotEmpty(damaged)
+	req.NoError(ioutil.WriteFile(damaged, []byte("damaged"), 0600))
+	results, err = Verify(tempDir, "")
+	req.NoError(err)
+	tester.Equal(VerifyStatusCorrupt, statuses(results)[blockPath])
+	validateDirectory(tester, blockPath)
+
+	results, err = Verify(tempDir, quarantineDir)
+	req.NoError(err)
+	for _, r := range results {
+		if r.Path == blockPath {
+			tester.Equal(VerifyStatusCorrupt, r.Status)
+			tester.NotEmpty(r.Problems)
+			tester.NotEmpty(r.QuarantinedPath)
+			validateDirectory(tester, r.QuarantinedPath)
+		}
+	}
+	_, err = os.Stat(blockPath)
+	tester.True(os.IsNotExist(err))
+
+	db = openDatabase(req, tempDir)
+	req.NoError(db.Close())
+}
+
+func TestSeekGlobalIndex(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			req.NoError(logger.Init(logger.Logging{
+				Env:   "dev",
+				Level: "warn",
+			}))
+			tempDir, deferFunc := test.Space(req)
+			defer deferFunc()
+			db, err := OpenDatabase(
+				context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+				DatabaseOpts{
+					Location: tempDir,
+					ShardNum: 1,
+					EncodingMethod: EncodingMethod{
+						EncoderPool: encoding.NewPlainEncoderPool(0),
+						DecoderPool: encoding.NewPlainDecoderPool(0),
+					},
+					BackfillWindow: 7 * 24 * time.Hour,
+					InMemory:       b.inMemory,
+				})
+			req.NoError(err)
+			defer db.Close()
+			s, err := db.Shard(0)
+			req.NoError(err)
+			series, err := s.Series().Get(Entity{Entry("productpage"), Entry("10.0.0.1")})
+			req.NoError(err)
+			field := index.Field{
+				Key:  index.FieldKey{IndexRuleID: 1},
+				Term: []byte("trace-1"),
+			}
+			now := time.Now()
+			past := now.AddDate(0, 0, -2)
+			for _, ts := range []time.Time{past, now} {
+				span, errSpan := series.Create(ts)
+				req.NoError(errSpan)
+				writer, errWriter := span.WriterBuilder().
+					Family([]byte("searchable"), []byte("v1")).
+					Time(ts).
+					Val([]byte("element")).
+					Build()
+				req.NoError(errWriter)
+				itemID, errWrite := writer.Write()
+				req.NoError(errWrite)
+				indexWriter, errIndex := s.Index().WriterBuilder().Time(ts).GlobalItemID(itemID).Build()
+				req.NoError(errIndex)
+				req.NoError(indexWriter.WriteLSMIndex(field))
+				req.NoError(span.Close())
+			}
+			req.Len(s.(*shard).segmentController.segments(), 2)
+			seek := func(timeRange TimeRange) []GlobalItemID {
+				ids, errSeek := s.Index().Seek(field, timeRange)
+				req.NoError(errSeek)
+				return ids
+			}
+			tester.Len(seek(NewTimeRange(now.AddDate(0, 0, -3), now.Add(time.Hour))), 2)
+			ids := seek(NewTimeRange(now.Add(-time.Hour), now.Add(time.Hour)))
+			req.Len(ids, 1)
+			tester.Equal(common.ItemID(now.UnixNano()), ids[0].ID)
+			ids = seek(NewTimeRange(now.AddDate(0, 0, -3), now.AddDate(0, 0, -1)))
+			req.Len(ids, 1)
+			tester.Equal(common.ItemID(past.UnixNano()), ids[0].ID)
+			tester.Empty(seek(NewTimeRangeDuration(now.Add(time.Hour), time.Hour)))
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	tempDir, deferFunc, db := setUp(req)
+	defer deferFunc()
+	snapshotDir, deferSnapshot := test.Space(req)
+	defer deferSnapshot()
+	restoredDir, deferRestored := test.Space(req)
+	defer deferRestored()
+	entity := Entity{Entry("productpage"), Entry("10.0.0.1")}
+	now := time.Now()
+	write := func(db Database, ts time.Time, val string) GlobalItemID {
+		shard, err := db.Shard(0)
+		req.NoError(err)
+		series, err := shard.Series().Get(entity)
+		req.NoError(err)
+		span, err := series.Create(ts)
+		req.NoError(err)
+		defer span.Close()
+		writer, err := span.WriterBuilder().
+			Family([]byte("searchable"), []byte(val)).
+			Time(ts).
+			Val([]byte(val)).
+			Build()
+		req.NoError(err)
+		itemID, err := writer.Write()
+		req.NoError(err)
+		return itemID
+	}
+	// the first element is flushed to the disk by closing the database
+	flushedID := write(db, now, "element-1")
+	req.NoError(db.Close())
+	db = openDatabase(req, tempDir)
+	memID := write(db, now.Add(time.Millisecond), "element-2")
+	req.NoError(db.Snapshot(snapshotDir))
+	write(db, now.Add(2*time.Millisecond), "element-3")
+	req.NoError(db.Close())
+
+	_, err := Restore(context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+		snapshotDir, DatabaseOpts{Location: snapshotDir})
+	tester.ErrorIs(err, ErrLocationNotEmpty)
+	db, err = Restore(
+		context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+		snapshotDir,
+		DatabaseOpts{
+			Location: restoredDir,
+			ShardNum: 1,
+			EncodingMethod: EncodingMethod{
+				EncoderPool: encoding.NewPlainEncoderPool(0),
+				DecoderPool: encoding.NewPlainDecoderPool(0),
+			},
+		})
+	req.NoError(err)
+	defer db.Close()
+	shard, err := db.Shard(0)
+	req.NoError(err)
+	series, err := shard.Series().Get(entity)
+	req.NoError(err)
+	req.Equal(flushedID.SeriesID, series.ID())
+	for id, want := range map[GlobalItemID]string{flushedID: "element-1", memID: "element-2"} {
+		item, closer, errGet := series.Get(id)
+		req.NoError(errGet)
+		val, errVal := item.Val()
+		req.NoError(errVal)
+		tester.Equal([]byte(want), val)
+		family, errFamily := item.Family("searchable")
+		req.NoError(errFamily)
+		tester.Equal([]byte(want), family)
+		req.NoError(closer.Close())
+	}
+
+	span, err := series.Span(NewTimeRangeDuration(now, time.Hour))
+	req.NoError(err)
+	defer span.Close()
+	seeker, err := span.SeekerBuilder().Build()
+	req.NoError(err)
+	iters, err := seeker.Seek()
+	req.NoError(err)
+	var count int
+	for _, iter := range iters {
+		for iter.Next() {
+			count++
+		}
+		req.NoError(iter.Close())
+	}
+	tester.Equal(2, count, "the data written after the snapshot shouldn't be restored")
+}
+
+func TestSnapshotWaitsForWrites(t *testing.T) {
+	req := require.New(t)
+	_, deferFunc, db := setUp(req)
+	defer deferFunc()
+	defer db.Close()
+	snapshotDir, deferSnapshot := test.Space(req)
+	defer deferSnapshot()
+	seg := db.Shards()[0].(*shard).segmentController.latest()
+	exit, err := seg.enterWrite()
+	req.NoError(err)
+	done := make(chan error)
+	go func() {
+		done <- db.Snapshot(snapshotDir)
+	}()
+	select {
+	case <-done:
+		req.Fail("the snapshot should wait for the ongoing write")
+	case <-time.After(100 * time.Millisecond):
+	}
+	exit()
+	select {
+	case err := <-done:
+		req.NoError(err)
+	case <-time.After(10 * time.Second):
+		req.Fail("the snapshot should be done once the write is done")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			tempDir, deferFunc, db := setUpWith(req, b)
+			defer deferFunc()
+			deleted := Entity{Entry("productpage"), Entry("10.0.0.1")}
+			kept := Entity{Entry("productpage"), Entry("10.0.0.2")}
+			field := index.Field{
+				Key:  index.FieldKey{IndexRuleID: 1},
+				Term: []byte("v1"),
+			}
+			now := time.Now()
+			write := func(entity Entity, ts time.Time) {
+				shard, err := db.Shard(0)
+				req.NoError(err)
+				series, err := shard.Series().Get(entity)
+				req.NoError(err)
+				span, err := series.Create(ts)
+				req.NoError(err)
+				defer span.Close()
+				writer, err := span.WriterBuilder().
+					Family([]byte("searchable"), []byte("v1")).
+					Time(ts).
+					Val([]byte("element")).
+					Build()
+				req.NoError(err)
+				itemID, err := writer.Write()
+				req.NoError(err)
+				indexWriter, err := shard.Index().WriterBuilder().Time(ts).GlobalItemID(itemID).Build()
+				req.NoError(err)
+				req.NoError(indexWriter.WriteInvertedIndex(field))
+			}
+			count := func(entity Entity) int {
+				shard, err := db.Shard(0)
+				req.NoError(err)
+				series, err := shard.Series().Get(entity)
+				req.NoError(err)
+				span, err := series.Span(NewTimeRangeDuration(now, time.Hour))
+				req.NoError(err)
+				defer span.Close()
+				seeker, err := span.SeekerBuilder().Build()
+				req.NoError(err)
+				iters, err := seeker.Seek()
+				req.NoError(err)
+				var num int
+				for _, iter := range iters {
+					for iter.Next() {
+						num++
+					}
+					req.NoError(iter.Close())
+				}
+				return num
+			}
+			seekIndex := func() int {
+				shard, err := db.Shard(0)
+				req.NoError(err)
+				ids, err := shard.Index().Seek(field, NewTimeRangeDuration(now, time.Hour))
+				req.NoError(err)
+				return len(ids)
+			}
+			for i := 0; i < 3; i++ {
+				write(deleted, now.Add(time.Duration(i)*time.Millisecond))
+			}
+			// an item ahead of now
+			write(deleted, now.Add(30*time.Minute))
+			write(kept, now.Add(3*time.Millisecond))
+			req.Equal(4, count(deleted))
+			req.Equal(5, seekIndex())
+
+			shard, err := db.Shard(0)
+			req.NoError(err)
+			series, err := shard.Series().Get(deleted)
+			req.NoError(err)
+			span, err := series.Span(NewTimeRange(now.Add(time.Millisecond), now.Add(10*time.Millisecond)))
+			req.NoError(err)
+			req.NoError(span.Delete())
+			req.NoError(span.Close())
+			tester.Equal(2, count(deleted))
+			tester.Equal(3, seekIndex())
+			tester.Equal(uint64(5), shard.Stats().ItemCount, "the items partly deleted from a block are kept in the stores")
+
+			req.NoError(shard.Series().Delete(deleted))
+			tester.Equal(0, count(deleted))
+			tester.Equal(1, count(kept))
+			tester.Equal(1, seekIndex())
+			tester.Equal(uint64(1), shard.Stats().ItemCount, "the deleted series should be dropped from the stores")
+			if b.inMemory {
+				req.NoError(db.Close())
+				return
+			}
+			req.NoError(db.Close())
+
+			db = openDatabase(req, tempDir)
+			defer db.Close()
+			tester.Equal(0, count(deleted), "the tombstones should survive reopening")
+			tester.Equal(1, count(kept))
+			tester.Equal(1, seekIndex())
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			_, deferFunc, db := setUpWith(req, b)
+			defer deferFunc()
+			defer db.Close()
+			shard, err := db.Shard(0)
+			req.NoError(err)
+			entities := []Entity{
+				{Entry("productpage"), Entry("10.0.0.1")},
+				{Entry("productpage"), Entry("10.0.0.2")},
+			}
+			now := time.Now()
+			count := func(entity Entity) int {
+				series, errSeries := shard.Series().Get(entity)
+				req.NoError(errSeries)
+				span, errSpan := series.Span(NewTimeRangeDuration(now, time.Hour))
+				req.NoError(errSpan)
+				defer span.Close()
+				seeker, errSeeker := span.SeekerBuilder().Build()
+				req.NoError(errSeeker)
+				iters, errSeek := seeker.Seek()
+				req.NoError(errSeek)
+				var num int
+				for _, iter := range iters {
+					for iter.Next() {
+						num++
+						val, errVal := iter.Val().Val()
+						req.NoError(errVal)
+						tester.Equal([]byte("element"), val)
+					}
+					req.NoError(iter.Close())
+				}
+				return num
+			}
+			batch := NewBatch()
+			spans := make([]SeriesSpan, 0, len(entities))
+			for _, entity := range entities {
+				series, errSeries := shard.Series().Get(entity)
+				req.NoError(errSeries)
+				span, errSpan := series.Create(now)
+				req.NoError(errSpan)
+				spans = append(spans, span)
+				for i := 0; i < 10; i++ {
+					ts := now.Add(time.Duration(i) * time.Millisecond)
+					writer, errWriter := span.WriterBuilder().
+						Family([]byte("searchable"), []byte("v1")).
+						Time(ts).
+						Val([]byte("element")).
+						Batch(batch).
+						Build()
+					req.NoError(errWriter)
+					_, errWriter = writer.Write()
+					req.NoError(errWriter)
+				}
+			}
+			tester.Equal(20, batch.Len())
+			for _, entity := range entities {
+				tester.Zero(count(entity))
+			}
+			req.NoError(batch.Commit())
+			tester.Zero(batch.Len())
+			for _, span := range spans {
+				req.NoError(span.Close())
+			}
+			for _, entity := range entities {
+				tester.Equal(10, count(entity))
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			_, deferFunc, db := setUpWith(req, b)
+			defer deferFunc()
+			defer db.Close()
+			shard, err := db.Shard(0)
+			req.NoError(err)
+			now := time.Now()
+			for _, entity := range []Entity{
+				{Entry("productpage"), Entry("10.0.0.1")},
+				{Entry("productpage"), Entry("10.0.0.2")},
+			} {
+				series, errSeries := shard.Series().Get(entity)
+				req.NoError(errSeries)
+				span, errSpan := series.Create(now)
+				req.NoError(errSpan)
+				for i := 0; i < 5; i++ {
+					writer, errWriter := span.WriterBuilder().
+						Family([]byte("searchable"), []byte("v1")).
+						Time(now.Add(time.Duration(i) * time.Millisecond)).
+						Val([]byte("element")).
+						Build()
+					req.NoError(errWriter)
+					_, errWriter = writer.Write()
+					req.NoError(errWriter)
+				}
+				req.NoError(span.Close())
+			}
+			stats := shard.Stats()
+			tester.Equal(common.ShardID(0), stats.ID)
+			tester.Equal(uint64(2), stats.SeriesCount)
+			tester.Equal(uint64(10), stats.ItemCount)
+			if !b.inMemory {
+				tester.Positive(stats.DiskSize)
+			}
+			req.Len(stats.Segments, 1)
+			var itemCount uint64
+			for _, b := range stats.Segments[0].Blocks {
+				itemCount += b.ItemCount
+				tester.Zero(b.IndexTermCount, "no index rule is defined")
+			}
+			tester.Equal(uint64(10), itemCount)
+			tester.Equal(uint64(10), stats.Segments[0].ItemCount)
+			pb := stats.ToProto(nil)
+			tester.Equal(uint64(10), pb.GetItemCount())
+			req.Len(pb.GetSegments(), 1)
+			tester.Nil(pb.GetSegments()[0].GetEndTime(), "the segment is not sealed")
+		})
+	}
+}
+
+func TestSeekByTime(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			tempDir, deferFunc, db := setUpWith(req, b)
+			defer deferFunc()
+			entity := Entity{Entry("productpage"), Entry("10.0.0.1")}
+			now := time.Now()
+			write := func(db Database, from int) {
+				shard, err := db.Shard(0)
+				req.NoError(err)
+				series, err := shard.Series().Get(entity)
+				req.NoError(err)
+				span, err := series.Create(now)
+				req.NoError(err)
+				for i := from; i < 10; i += 2 {
+					writer, errWriter := span.WriterBuilder().
+						Family([]byte("searchable"), []byte(fmt.Sprintf("v%d", i))).
+						Time(now.Add(time.Duration(i) * time.Millisecond)).
+						Val([]byte("element")).
+						Build()
+					req.NoError(errWriter)
+					_, errWriter = writer.Write()
+					req.NoError(errWriter)
+				}
+				req.NoError(span.Close())
+			}
+			// the even ones are flushed into the encoded chunks while closing, the odd ones stay in the memory tables
+			write(db, 0)
+			if !b.inMemory {
+				req.NoError(db.Close())
+				db = openDatabase(req, tempDir)
+			}
+			defer db.Close()
+			write(db, 1)
+
+			shard, err := db.Shard(0)
+			req.NoError(err)
+			series, err := shard.Series().Get(entity)
+			req.NoError(err)
+			seek := func(order modelv1.Sort) (families []string) {
+				span, errSpan := series.Span(NewTimeRangeDuration(now.Add(2*time.Millisecond), 5*time.Millisecond))
+				req.NoError(errSpan)
+				defer span.Close()
+				seeker, errSeeker := span.SeekerBuilder().OrderByTime(order).Build()
+				req.NoError(errSeeker)
+				iters, errSeek := seeker.Seek()
+				req.NoError(errSeek)
+				for _, iter := range iters {
+					for iter.Next() {
+						family, errFamily := iter.Val().Family("searchable")
+						req.NoError(errFamily)
+						families = append(families, string(family))
+					}
+					req.NoError(iter.Close())
+				}
+				return families
+			}
+			tester.Equal([]string{"v2", "v3", "v4", "v5", "v6"}, seek(modelv1.Sort_SORT_ASC))
+			tester.Equal([]string{"v6", "v5", "v4", "v3", "v2"}, seek(modelv1.Sort_SORT_DESC))
+		})
+	}
+}
+
+func TestSeekByIndex(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			req.NoError(logger.Init(logger.Logging{
+				Env:   "dev",
+				Level: "warn",
+			}))
+			tempDir, deferFunc := test.Space(req)
+			defer deferFunc()
+			rule := &databasev1.IndexRule{
+				Metadata: &commonv1.Metadata{Id: 1},
+				Type:     databasev1.IndexRule_TYPE_TREE,
+			}
+			db, err := OpenDatabase(
+				context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+				DatabaseOpts{
+					Location:   tempDir,
+					ShardNum:   1,
+					IndexRules: []*databasev1.IndexRule{rule},
+					EncodingMethod: EncodingMethod{
+						EncoderPool: encoding.NewPlainEncoderPool(0),
+						DecoderPool: encoding.NewPlainDecoderPool(0),
+					},
+					InMemory: b.inMemory,
+				})
+			req.NoError(err)
+			defer db.Close()
+			shard, err := db.Shard(0)
+			req.NoError(err)
+			series, err := shard.Series().Get(Entity{Entry("productpage"), Entry("10.0.0.1")})
+			req.NoError(err)
+			now := time.Now()
+			span, err := series.Create(now)
+			req.NoError(err)
+			for i := 0; i < 3; i++ {
+				writer, errWriter := span.WriterBuilder().
+					Family([]byte("searchable"), []byte(fmt.Sprintf("v%d", i))).
+					Time(now.Add(time.Duration(i) * time.Millisecond)).
+					Val([]byte("element")).
+					Build()
+				req.NoError(errWriter)
+				itemID, errWrite := writer.Write()
+				req.NoError(errWrite)
+				// the larger duration is written earlier
+				req.NoError(span.(*seriesSpan).blocks[0].writeLSMIndex(index.Field{
+					Key:  index.FieldKey{SeriesID: series.ID(), IndexRuleID: 1},
+					Term: convert.Int64ToBytes(int64(10 - i)),
+				}, itemID.ID))
+			}
+			req.NoError(span.Close())
+
+			seek := func() (families []string) {
+				span, errSpan := series.Span(NewTimeRangeDuration(now, time.Second))
+				req.NoError(errSpan)
+				defer span.Close()
+				seeker, errSeeker := span.SeekerBuilder().OrderByIndex(rule, modelv1.Sort_SORT_ASC).Build()
+				req.NoError(errSeeker)
+				iters, errSeek := seeker.Seek()
+				req.NoError(errSeek)
+				for _, iter := range iters {
+					for iter.Next() {
+						family, errFamily := iter.Val().Family("searchable")
+						req.NoError(errFamily)
+						families = append(families, string(family))
+					}
+					req.NoError(iter.Close())
+				}
+				return families
+			}
+			tester.Equal([]string{"v2", "v1", "v0"}, seek())
+			tester.Equal([]string{"v2", "v1", "v0"}, seek(), "closing the iterators should leave the block open")
+		})
+	}
+}
+
+func TestInMemory(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	tempDir, deferFunc, _ := setUp(req)
+	defer deferFunc()
+	open := func() Database {
+		db, err := OpenDatabase(
+			context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+			DatabaseOpts{
+				Location: tempDir + "/mem",
+				ShardNum: 1,
+				EncodingMethod: EncodingMethod{
+					EncoderPool: encoding.NewPlainEncoderPool(0),
+					DecoderPool: encoding.NewPlainDecoderPool(0),
+				},
+				InMemory: true,
+			})
+		req.NoError(err)
+		return db
+	}
+	db := open()
+	shard, err := db.Shard(0)
+	req.NoError(err)
+	now := time.Now()
+	for _, entity := range []Entity{
+		{Entry("productpage"), Entry("10.0.0.1")},
+		{Entry("productpage"), Entry("10.0.0.2")},
+	} {
+		series, errSeries := shard.Series().Get(entity)
+		req.NoError(errSeries)
+		span, errSpan := series.Create(now)
+		req.NoError(errSpan)
+		for i := 0; i < 5; i++ {
+			writer, errWriter := span.WriterBuilder().
+				Family([]byte("searchable"), []byte("v1")).
+				Time(now.Add(time.Duration(i) * time.Millisecond)).
+				Val([]byte("element")).
+				Build()
+			req.NoError(errWriter)
+			_, errWriter = writer.Write()
+			req.NoError(errWriter)
+		}
+		req.NoError(span.Close())
+	}
+	stats := shard.Stats()
+	tester.Equal(uint64(2), stats.SeriesCount)
+	tester.Equal(uint64(10), stats.ItemCount)
+	tester.Zero(stats.DiskSize)
+	req.NoError(db.Close())
+	tester.NoDirExists(tempDir+"/mem", "nothing is created in the location")
+	db = open()
+	defer db.Close()
+	shard, err = db.Shard(0)
+	req.NoError(err)
+	tester.Zero(shard.Stats().SeriesCount, "the data is lost after closing")
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   databasev1.DuplicatePolicy
+		wantErr  bool
+		wantVals []string
+	}{
+		{
+			name:     "overwrite",
+			policy:   databasev1.DuplicatePolicy_DUPLICATE_POLICY_UNSPECIFIED,
+			wantVals: []string{"element-2", "element-3"},
+		},
+		{
+			name:     "reject",
+			policy:   databasev1.DuplicatePolicy_DUPLICATE_POLICY_REJECT,
+			wantErr:  true,
+			wantVals: []string{"element-1", "element-3"},
+		},
+		{
+			name:     "keep",
+			policy:   databasev1.DuplicatePolicy_DUPLICATE_POLICY_KEEP,
+			wantVals: []string{"element-1", "element-2", "element-3"},
+		},
+	}
+	for _, tt := range tests {
+		for _, b := range backends {
+			t.Run(tt.name+"/"+b.name, func(t *testing.T) {
+				req := require.New(t)
+				tester := assert.New(t)
+				tempDir, deferFunc := test.Space(req)
+				defer deferFunc()
+				db, err := OpenDatabase(
+					context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
+					DatabaseOpts{
+						Location: tempDir,
+						ShardNum: 1,
+						EncodingMethod: EncodingMethod{
+							EncoderPool: encoding.NewPlainEncoderPool(0),
+							DecoderPool: encoding.NewPlainDecoderPool(0),
+						},
+						DuplicatePolicy: tt.policy,
+						InMemory:        b.inMemory,
+					})
+				req.NoError(err)
+				defer db.Close()
+				shard, err := db.Shard(0)
+				req.NoError(err)
+				series, err := shard.Series().Get(Entity{Entry("productpage"), Entry("10.0.0.1")})
+				req.NoError(err)
+				now := time.Now()
+				write := func(ts time.Time, val string) (GlobalItemID, error) {
+					span, errSpan := series.Create(ts)
+					req.NoError(errSpan)
+					defer span.Close()
+					writer, errWriter := span.WriterBuilder().
+						Time(ts).
+						Val([]byte(val)).
+						Source([]byte(val)).
+						Build()
+					req.NoError(errWriter)
+					return writer.Write()
+				}
+				id1, err := write(now, "element-1")
+				req.NoError(err)
+				id2, err := write(now, "element-2")
+				if tt.wantErr {
+					tester.ErrorIs(err, ErrDuplicatedItem)
+				} else {
+					req.NoError(err)
+				}
+				if tt.policy == databasev1.DuplicatePolicy_DUPLICATE_POLICY_KEEP {
+					tester.NotEqual(id1.ID, id2.ID)
+				}
+				// the item right after the duplicated ones has its own id
+				id3, err := write(now.Add(time.Nanosecond), "element-3")
+				req.NoError(err)
+				tester.NotEqual(id2.ID, id3.ID)
+				if tt.policy != databasev1.DuplicatePolicy_DUPLICATE_POLICY_UNSPECIFIED {
+					// writing from the same source again takes the written item
+					again, errAgain := write(now, "element-1")
+					req.NoError(errAgain)
+					tester.Equal(id1.ID, again.ID)
+				}
+
+				span, err := series.Span(NewTimeRangeDuration(now, time.Millisecond))
+				req.NoError(err)
+				defer span.Close()
+				seeker, err := span.SeekerBuilder().OrderByTime(modelv1.Sort_SORT_ASC).Build()
+				req.NoError(err)
+				iters, err := seeker.Seek()
+				req.NoError(err)
+				var vals []string
+				var times []uint64
+				for _, iter := range iters {
+					for iter.Next() {
+						val, errVal := iter.Val().Val()
+						req.NoError(errVal)
+						vals = append(vals, string(val))
+						times = append(times, iter.Val().Time())
+					}
+					req.NoError(iter.Close())
+				}
+				tester.Equal(tt.wantVals, vals)
+				wantTimes := make([]uint64, len(vals))
+				for i := range wantTimes {
+					wantTimes[i] = uint64(now.UnixNano())
+				}
+				wantTimes[len(wantTimes)-1]++
+				tester.Equal(wantTimes, times)
+			})
+		}
+	}
+}
+
+func TestReshard(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	req.NoError(logger.Init(logger.Logging{
+		Env:   "dev",
+		Level: "warn",
+	}))
+	tempDir, deferFunc := test.Space(req)
+	defer deferFunc()
+	ctx := context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test"))
+	// the resources sharing the root have their own locations
+	location := tempDir + "/default/sw"
+	siblingLocation := tempDir + "/default/other"
+	optsWithShardNum := func(shardNum uint32) DatabaseOpts {
+		return DatabaseOpts{
+			Location:        location,
+			ShardNum:        shardNum,
+			DuplicatePolicy: databasev1.DuplicatePolicy_DUPLICATE_POLICY_KEEP,
+			EncodingMethod: EncodingMethod{
+				EncoderPool: encoding.NewPlainEncoderPool(0),
+				DecoderPool: encoding.NewPlainDecoderPool(0),
+			},
+		}
+	}
+	locate := func(shardNum uint32) func(entity Entity) (common.ShardID, error) {
+		return func(entity Entity) (common.ShardID, error) {
+			return common.ShardID(convert.Hash(entity.Marshal()) % uint64(shardNum)), nil
+		}
+	}
+	entities := make([]Entity, 0, 10)
+	for i := 0; i < 10; i++ {
+		entities = append(entities, Entity{Entry("productpage"), Entry(fmt.Sprintf("10.0.0.%d", i))})
+	}
+	now := time.Now()
+	db, err := OpenDatabase(ctx, optsWithShardNum(2))
+	req.NoError(err)
+	for _, entity := range entities {
+		shardID, errLocate := locate(2)(entity)
+		req.NoError(errLocate)
+		shard, errShard := db.Shard(shardID)
+		req.NoError(errShard)
+		series, errSeries := shard.Series().Get(entity)
+		req.NoError(errSeries)
+		span, errSpan := series.Create(now)
+		req.NoError(errSpan)
+		// the items sharing the timestamp are kept
+		for i := 0; i < 2; i++ {
+			writer, errWriter := span.WriterBuilder().
+				Family([]byte("searchable"), entity[1]).
+				Time(now).
+				Val([]byte("element")).
+				Build()
+			req.NoError(errWriter)
+			_, errWriter = writer.Write()
+			req.NoError(errWriter)
+		}
+		req.NoError(span.Close())
+	}
+	req.NoError(db.Close())
+	// other files in the location should survive the resharding
+	req.NoError(os.MkdirAll(location+"/wal", 0o700))
+	siblingOpts := optsWithShardNum(2)
+	siblingOpts.Location = siblingLocation
+	sibling, err := OpenDatabase(ctx, siblingOpts)
+	req.NoError(err)
+	req.NoError(sibling.Close())
+
+	var written int
+	req.NoError(Reshard(ctx, optsWithShardNum(3), ReshardOpts{
+		Families: [][]byte{nil, []byte("searchable")},
+		Locate:   locate(3),
+		OnWrite: func(_ Database, _ Item, _ Writer) error {
+			written++
+			return nil
+		},
+	}))
+	tester.Equal(2*len(entities), written)
+	_, err = os.Stat(location + reshardingSuffix)
+	tester.True(os.IsNotExist(err))
+	_, err = os.Stat(location + "/wal")
+	tester.NoError(err)
+	siblingShardNum, err := shardNumInLocation(siblingLocation)
+	req.NoError(err)
+	tester.Equal(uint32(2), siblingShardNum, "the shards of other resources should be left alone")
+
+	db, err = OpenDatabase(ctx, optsWithShardNum(3))
+	req.NoError(err)
+	defer db.Close()
+	for _, entity := range entities {
+		shardID, errLocate := locate(3)(entity)
+		req.NoError(errLocate)
+		shard, errShard := db.Shard(shardID)
+		req.NoError(errShard)
+		list, errList := shard.Series().List(NewPath(entity))
+		req.NoError(errList)
+		req.Len(list, 1)
+		tester.Equal(entity, list[0].Entity())
+		span, errSpan := list[0].Span(NewTimeRangeDuration(now, time.Millisecond))
+		req.NoError(errSpan)
+		seeker, errSeeker := span.SeekerBuilder().Build()
+		req.NoError(errSeeker)
+		iters, errSeek := seeker.Seek()
+		req.NoError(errSeek)
+		var num int
+		for _, iter := range iters {
+			for iter.Next() {
+				num++
+				family, errFamily := iter.Val().Family("searchable")
+				req.NoError(errFamily)
+				tester.Equal([]byte(entity[1]), family)
+			}
+			req.NoError(iter.Close())
+		}
+		tester.Equal(2, num, "the duplicated items shouldn't overwrite each other")
+		req.NoError(span.Close())
+	}
+}
+
+func TestReopenDatabase(t *testing.T) {
+	req := require.New(t)
+	tempDir, deferFunc, db := setUp(req)
+	defer deferFunc()
+	entity := Entity{Entry("productpage"), Entry("10.0.0.1")}
+	now := time.Now()
+	shard, err := db.Shard(0)
+	req.NoError(err)
+	series, err := shard.Series().Get(entity)
+	req.NoError(err)
+	span, err := series.Span(NewTimeRangeDuration(now, 0))
+	req.NoError(err)
+	writer, err := span.WriterBuilder().
+		Family([]byte("searchable"), []byte("v1")).
+		Time(now).
+		Val([]byte("element-1")).
+		Build()
+	req.NoError(err)
+	itemID, err := writer.Write()
+	req.NoError(err)
+	req.NoError(span.Close())
+	req.NoError(db.Close())
+
+	db = openDatabase(req, tempDir)
+	defer db.Close()
+	req.Len(db.Shards(), 1)
+	shard, err = db.Shard(0)
+	req.NoError(err)
+	series, err = shard.Series().Get(entity)
+	req.NoError(err)
+	req.Equal(itemID.SeriesID, series.ID())
+	item, closer, err := series.Get(itemID)
+	req.NoError(err)
+	defer closer.Close()
+	val, err := item.Val()
+	req.NoError(err)
+	req.Equal([]byte("element-1"), val)
+	family, err := item.Family("searchable")
+	req.NoError(err)
+	req.Equal([]byte("v1"), family)
+}
+
+// backends run the behavior tests over the stores on the disk and the ones kept in the memory.
+// A database in the memory isn't reopened since its data is lost after closing.
+var backends = []backend{
+	{name: "badger"},
+	{name: "in-memory", inMemory: true},
+}
+
+type backend struct {
+	name     string
+	inMemory bool
 }
 
 func setUp(t *require.Assertions) (tempDir string, deferFunc func(), db Database) {
+	return setUpWith(t, backends[0])
+}
+
+func setUpWith(t *require.Assertions, b backend) (tempDir string, deferFunc func(), db Database) {
 	t.NoError(logger.Init(logger.Logging{
 		Env:   "dev",
 		Level: "warn",
 	}))
 	tempDir, deferFunc = test.Space(t)
+	return tempDir, deferFunc, openDatabaseWith(t, tempDir, b)
+}
+
+func openDatabase(t *require.Assertions, path string) (db Database) {
+	return openDatabaseWith(t, path, backends[0])
+}
+
+func openDatabaseWith(t *require.Assertions, path string, b backend) (db Database) {
 	db, err := OpenDatabase(
 		context.WithValue(context.Background(), logger.ContextKey, logger.GetLogger("test")),
 		DatabaseOpts{
-			Location: tempDir,
+			Location: path,
 			ShardNum: 1,
 			EncodingMethod: EncodingMethod{
 				EncoderPool: encoding.NewPlainEncoderPool(0),
 				DecoderPool: encoding.NewPlainDecoderPool(0),
 			},
+			InMemory: b.inMemory,
 		})
 	t.NoError(err)
 	t.NotNil(db)
-	return tempDir, deferFunc, db
+	return db
 }
 
 func validateDirectory(t *assert.Assertions, dir string) {
@@ -0,0 +1,152 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+type VerifyStatus int
+
+const (
+	VerifyStatusOK VerifyStatus = iota
+	// VerifyStatusUnverified means there is no manifest, the block or the segment isn't sealed or isn't closed properly
+	VerifyStatusUnverified
+	VerifyStatusCorrupt
+)
+
+func (s VerifyStatus) String() string {
+	switch s {
+	case VerifyStatusOK:
+		return "ok"
+	case VerifyStatusUnverified:
+		return "unverified"
+	case VerifyStatusCorrupt:
+		return "corrupt"
+	}
+	return "unknown"
+}
+
+// VerifyResult is the result of a block or a segment
+type VerifyResult struct {
+	Path     string
+	Status   VerifyStatus
+	Problems []string
+	// QuarantinedPath is where the corrupt one is moved to, it's empty if it isn't quarantined
+	QuarantinedPath string
+}
+
+// Verify checks the segments and the blocks in the location against their manifests while the database is closed.
+// The corrupt ones are moved into the quarantine dir if it isn't empty, then the database opens without them.
+func Verify(location, quarantineDir string) ([]VerifyResult, error) {
+	var results []VerifyResult
+	err := walkDir(location, shardPathPrefix, func(_, shardPath string) error {
+		return walkDir(shardPath, segPathPrefix, func(_, segPath string) error {
+			segResult, err := verifyDir(segPath, segmentDirs)
+			if err != nil {
+				return err
+			}
+			if segResult.Status == VerifyStatusCorrupt {
+				// the blocks are quarantined along with the segment
+				results = append(results, quarantine(location, quarantineDir, segResult))
+				return nil
+			}
+			results = append(results, segResult)
+			return walkDir(segPath, blockPathPrefix, func(_, blockPath string) error {
+				blockResult, errBlock := verifyDir(blockPath, blockDirs)
+				if errBlock != nil {
+					return errBlock
+				}
+				if blockResult.Status == VerifyStatusCorrupt {
+					blockResult = quarantine(location, quarantineDir, blockResult)
+				}
+				results = append(results, blockResult)
+				return nil
+			})
+		})
+	})
+	return results, err
+}
+
+// verifyDir checks the required stores exist, which are the first two of the dirs, and the files match the manifest
+func verifyDir(path string, dirs []string) (VerifyResult, error) {
+	result := VerifyResult{
+		Path:   path,
+		Status: VerifyStatusOK,
+	}
+	for _, dir := range dirs[:2] {
+		if _, err := os.Stat(filepath.Join(path, dir)); os.IsNotExist(err) {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s is missing", dir))
+		}
+	}
+	m, err := readManifest(path)
+	if os.IsNotExist(errors.Cause(err)) {
+		if len(result.Problems) > 0 {
+			result.Status = VerifyStatusCorrupt
+		} else {
+			result.Status = VerifyStatusUnverified
+		}
+		return result, nil
+	}
+	if err != nil {
+		result.Status = VerifyStatusCorrupt
+		result.Problems = append(result.Problems, err.Error())
+		return result, nil
+	}
+	problems, err := verifyManifest(path, m, dirs...)
+	if err != nil {
+		return result, err
+	}
+	result.Problems = append(result.Problems, problems...)
+	if len(result.Problems) > 0 {
+		result.Status = VerifyStatusCorrupt
+	}
+	return result, nil
+}
+
+func quarantine(location, quarantineDir string, result VerifyResult) VerifyResult {
+	if quarantineDir == "" {
+		return result
+	}
+	rel, err := filepath.Rel(location, result.Path)
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+		return result
+	}
+	target := filepath.Join(quarantineDir, rel)
+	if err = os.MkdirAll(filepath.Dir(target), dirPerm); err != nil {
+		result.Problems = append(result.Problems, err.Error())
+		return result
+	}
+	// the quarantine dir might be on another disk
+	if err = os.Rename(result.Path, target); err != nil {
+		if err = copyDir(result.Path, target); err == nil {
+			err = os.RemoveAll(result.Path)
+		}
+	}
+	if err != nil {
+		result.Problems = append(result.Problems, errors.Wrapf(err, "failed to quarantine").Error())
+		return result
+	}
+	result.QuarantinedPath = target
+	return result
+}
@@ -0,0 +1,185 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/apache/skywalking-banyandb/api/common"
+	commonv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/common/v1"
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+	"github.com/apache/skywalking-banyandb/pkg/logger"
+	"github.com/apache/skywalking-banyandb/pkg/wal"
+)
+
+const (
+	// walRootTemplate is <root>/<catalog>/wal/<group>/<name>
+	walRootTemplate = "%s/%s/wal/%s/%s"
+	walTemplate     = "%s/shard-%d"
+	walPathPrefix   = "shard-"
+)
+
+type WALOpts struct {
+	Root string
+	// Catalog is part of the path, the resources of different catalogs might share a name
+	Catalog    string
+	Metadata   *commonv1.Metadata
+	ShardNum   uint32
+	SyncWrites bool
+	// Replay decodes an entry left by the last run and applies it again, source identifies the entry.
+	// cb should be invoked once both the data and the indices are written.
+	Replay func(data []byte, source []byte, cb func()) error
+}
+
+// WAL holds the write-ahead logs of the shards of a resource
+type WAL struct {
+	l    *logger.Logger
+	logs map[common.ShardID]*wal.Log
+}
+
+// OpenWAL replays the write-ahead logs left by the last run, then opens a log for each shard.
+// The logs of the shards beyond the shard number are removed once they are replayed.
+func OpenWAL(ctx context.Context, opts WALOpts) (*WAL, error) {
+	w := &WAL{
+		l:    logger.GetLogger("wal"),
+		logs: make(map[common.ShardID]*wal.Log, opts.ShardNum),
+	}
+	if pl, ok := ctx.Value(logger.ContextKey).(*logger.Logger); ok {
+		w.l = pl.Named("wal")
+	}
+	walRoot := fmt.Sprintf(walRootTemplate, opts.Root, opts.Catalog, opts.Metadata.GetGroup(), opts.Metadata.GetName())
+	entries, err := ioutil.ReadDir(walRoot)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read %s", walRoot)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), walPathPrefix) {
+			continue
+		}
+		id, errParse := strconv.Atoi(strings.TrimPrefix(entry.Name(), walPathPrefix))
+		if errParse != nil {
+			return nil, multierr.Append(errors.Wrapf(errParse, "invalid shard id: %s", entry.Name()), w.Close())
+		}
+		path := fmt.Sprintf(walTemplate, walRoot, id)
+		l, errOpen := wal.Open(path, wal.Options{SyncWrites: opts.SyncWrites})
+		if errOpen != nil {
+			return nil, multierr.Append(errOpen, w.Close())
+		}
+		w.replay(common.ShardID(id), l, opts.Replay)
+		if uint32(id) < opts.ShardNum {
+			w.logs[common.ShardID(id)] = l
+			continue
+		}
+		if l.Pending() > 0 {
+			w.logs[common.ShardID(id)] = l
+			w.l.Warn().Int("shard_id", id).Msg("keep the write-ahead log of the removed shard which isn't fully replayed")
+			continue
+		}
+		_ = l.Close()
+		if errRemove := os.RemoveAll(path); errRemove != nil {
+			return nil, multierr.Append(errors.Wrapf(errRemove, "failed to remove %s", path), w.Close())
+		}
+	}
+	for i := uint32(0); i < opts.ShardNum; i++ {
+		if _, ok := w.logs[common.ShardID(i)]; ok {
+			continue
+		}
+		l, errOpen := wal.Open(fmt.Sprintf(walTemplate, walRoot, i), wal.Options{SyncWrites: opts.SyncWrites})
+		if errOpen != nil {
+			return nil, multierr.Append(errOpen, w.Close())
+		}
+		w.logs[common.ShardID(i)] = l
+	}
+	return w, nil
+}
+
+// replay applies the entries whose data or indices might be incomplete.
+// The entries might be routed to other shards once the shard number is changed.
+// An entry whose data is written takes the written item, then its indices are written again.
+func (w *WAL) replay(walShardID common.ShardID, l *wal.Log, fn func(data []byte, source []byte, cb func()) error) {
+	var replayed int
+	err := l.Replay(func(seq uint64, data []byte) error {
+		waitCh := make(chan struct{})
+		err := fn(data, walSource(walShardID, seq), func() {
+			close(waitCh)
+		})
+		// the entry was rejected by another item, as it was when it's written
+		if errors.Is(err, ErrDuplicatedItem) {
+			w.l.Warn().Err(err).Uint64("seq", seq).Msg("drop the rejected write-ahead log entry")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		<-waitCh
+		replayed++
+		return nil
+	})
+	if err != nil {
+		w.l.Error().Err(err).Msg("failed to replay the write-ahead log")
+	}
+	if replayed > 0 {
+		w.l.Info().Int("num", replayed).Msg("replayed the write-ahead log")
+	}
+}
+
+// Write records the data in the write-ahead log of the shard before applying it.
+// The entry is done once apply invokes the callback, and the entry of a failed apply isn't replayed.
+func (w *WAL) Write(shardID common.ShardID, data []byte, apply func(source []byte, cb func()) error) error {
+	l, ok := w.logs[shardID]
+	if !ok {
+		return errors.WithMessagef(ErrInvalidShardID, "shard: %d", shardID)
+	}
+	seq, err := l.Append(data)
+	if err != nil {
+		return err
+	}
+	err = apply(walSource(shardID, seq), func() {
+		if errDone := l.Done(seq); errDone != nil {
+			w.l.Warn().Err(errDone).Uint64("seq", seq).Msg("failed to mark the write-ahead log entry as done")
+		}
+	})
+	if err != nil {
+		_ = l.Done(seq)
+	}
+	return err
+}
+
+func (w *WAL) Close() (err error) {
+	for _, l := range w.logs {
+		err = multierr.Append(err, l.Close())
+	}
+	return err
+}
+
+// walSource identifies an entry in the write-ahead log of a shard, which is written along with the item
+func walSource(shardID common.ShardID, seq uint64) []byte {
+	return bytes.Join([][]byte{
+		convert.Uint32ToBytes(uint32(shardID)),
+		convert.Uint64ToBytes(seq),
+	}, nil)
+}
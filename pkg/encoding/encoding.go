@@ -70,3 +70,80 @@ type SeriesIterator interface {
 	// Error might return an error indicates a decode failure
 	Error() error
 }
+
+// KeySelector picks the series by their keys
+type KeySelector = func(key []byte) bool
+
+type selectiveEncoderPool struct {
+	selector KeySelector
+	selected SeriesEncoderPool
+	others   SeriesEncoderPool
+}
+
+// NewSelectiveEncoderPool gets the encoders from the selected pool for the series picked by the selector,
+// and from the others pool for the rest
+func NewSelectiveEncoderPool(selector KeySelector, selected, others SeriesEncoderPool) SeriesEncoderPool {
+	return &selectiveEncoderPool{
+		selector: selector,
+		selected: selected,
+		others:   others,
+	}
+}
+
+func (p *selectiveEncoderPool) Get(metadata []byte) SeriesEncoder {
+	pool := p.others
+	if p.selector(metadata) {
+		pool = p.selected
+	}
+	return &selectedEncoder{
+		SeriesEncoder: pool.Get(metadata),
+		pool:          pool,
+	}
+}
+
+func (p *selectiveEncoderPool) Put(encoder SeriesEncoder) {
+	se := encoder.(*selectedEncoder)
+	se.pool.Put(se.SeriesEncoder)
+}
+
+// selectedEncoder remembers the pool it comes from
+type selectedEncoder struct {
+	SeriesEncoder
+	pool SeriesEncoderPool
+}
+
+type selectiveDecoderPool struct {
+	selector KeySelector
+	selected SeriesDecoderPool
+	others   SeriesDecoderPool
+}
+
+// NewSelectiveDecoderPool is the counterpart of NewSelectiveEncoderPool
+func NewSelectiveDecoderPool(selector KeySelector, selected, others SeriesDecoderPool) SeriesDecoderPool {
+	return &selectiveDecoderPool{
+		selector: selector,
+		selected: selected,
+		others:   others,
+	}
+}
+
+func (p *selectiveDecoderPool) Get(metadata []byte) SeriesDecoder {
+	pool := p.others
+	if p.selector(metadata) {
+		pool = p.selected
+	}
+	return &selectedDecoder{
+		SeriesDecoder: pool.Get(metadata),
+		pool:          pool,
+	}
+}
+
+func (p *selectiveDecoderPool) Put(decoder SeriesDecoder) {
+	sd := decoder.(*selectedDecoder)
+	sd.pool.Put(sd.SeriesDecoder)
+}
+
+type selectedDecoder struct {
+	SeriesDecoder
+	pool SeriesDecoderPool
+}
@@ -20,6 +20,10 @@ package encoding
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/apache/skywalking-banyandb/pkg/bit"
@@ -29,159 +33,323 @@ import (
 
 var (
 	_ SeriesEncoder = (*intEncoder)(nil)
+	_ SeriesDecoder = (*intDecoder)(nil)
+
+	intEncoders = sync.Pool{
+		New: func() interface{} {
+			return &intEncoder{}
+		},
+	}
+	intDecoders = sync.Pool{
+		New: func() interface{} {
+			return &intDecoder{}
+		},
+	}
+)
+
+const (
+	// intTailLen equals interval(uint64) + the latest time(uint64) + num(uint32)
+	intTailLen = 8 + 8 + 4
+	// maxEmptySlots bounds the empty slots per point, beyond which writing the time deltas is cheaper
+	maxEmptySlots = 8
 )
 
+// ParseInterval returns the interval of the series identified by the key, zero means the interval is unknown
 type ParseInterval = func(key []byte) time.Duration
 
+type intEncoderPool struct {
+	pool *sync.Pool
+	size int
+	fn   ParseInterval
+}
+
+// NewIntEncoderPool returns a pool of the encoders compressing 8-byte integers.
+// The size is the number of the points in a chunk.
+func NewIntEncoderPool(size int, fn ParseInterval) SeriesEncoderPool {
+	return &intEncoderPool{
+		pool: &intEncoders,
+		size: size,
+		fn:   fn,
+	}
+}
+
+func (p *intEncoderPool) Get(metadata []byte) SeriesEncoder {
+	encoder := p.pool.Get().(*intEncoder)
+	encoder.size = p.size
+	encoder.fn = p.fn
+	encoder.Reset(metadata)
+	return encoder
+}
+
+func (p *intEncoderPool) Put(encoder SeriesEncoder) {
+	p.pool.Put(encoder)
+}
+
+type intDecoderPool struct {
+	pool *sync.Pool
+	size int
+}
+
+// NewIntDecoderPool returns a pool of the decoders of the chunks encoded by the pool of NewIntEncoderPool
+func NewIntDecoderPool(size int) SeriesDecoderPool {
+	return &intDecoderPool{
+		pool: &intDecoders,
+		size: size,
+	}
+}
+
+func (p *intDecoderPool) Get(_ []byte) SeriesDecoder {
+	decoder := p.pool.Get().(*intDecoder)
+	decoder.size = p.size
+	return decoder
+}
+
+func (p *intDecoderPool) Put(decoder SeriesDecoder) {
+	p.pool.Put(decoder)
+}
+
+type intPoint struct {
+	ts  uint64
+	val uint64
+}
+
+// intEncoder compresses the values with XOR, and the timestamps with the interval of the series.
+//
+// The points are written from the latest one. If all of them are aligned to the interval, a bit denotes whether a slot
+// of the interval has a point. Otherwise, the delta of every timestamp from the previous one is written.
 type intEncoder struct {
-	buff      *bytes.Buffer
-	bw        *bit.Writer
-	values    *XOREncoder
 	fn        ParseInterval
 	interval  time.Duration
+	points    []intPoint
 	startTime uint64
-	num       int
 	size      int
 }
 
+// NewIntEncoder creates an encoder compressing 8-byte integers, the size is the number of the points in a chunk
 func NewIntEncoder(size int, fn ParseInterval) SeriesEncoder {
-	buff := &bytes.Buffer{}
-	bw := bit.NewWriter(buff)
 	return &intEncoder{
-		buff:   buff,
-		bw:     bw,
-		values: NewXOREncoder(bw),
-		fn:     fn,
-		size:   size,
+		fn:   fn,
+		size: size,
 	}
 }
 
+// Append ignores the values which aren't 8-byte integers
 func (ie *intEncoder) Append(ts uint64, value []byte) {
-	if len(value) > 8 {
+	if len(value) != 8 {
 		return
 	}
-	if ie.startTime == 0 {
+	if len(ie.points) == 0 || ts < ie.startTime {
 		ie.startTime = ts
 	}
-	gap := int(ts) - int(ie.startTime)
-	if gap < 0 {
-		return
-	}
-	zeroNum := gap/int(ie.interval) - 1
-	for i := 0; i < zeroNum; i++ {
-		ie.bw.WriteBool(false)
-		ie.num++
-	}
-	ie.bw.WriteBool(len(value) > 0)
-	ie.values.Write(binary.LittleEndian.Uint64(value))
-	ie.num++
+	ie.points = append(ie.points, intPoint{ts: ts, val: convert.BytesToUint64(value)})
 }
 
 func (ie *intEncoder) IsFull() bool {
-	return ie.num >= ie.size
+	return len(ie.points) >= ie.size
 }
 
 func (ie *intEncoder) Reset(key []byte) {
-	ie.bw.Reset(nil)
-	ie.interval = ie.fn(key)
+	ie.points = ie.points[:0]
+	ie.startTime = 0
+	ie.interval = 0
+	if ie.fn != nil {
+		ie.interval = ie.fn(key)
+	}
 }
 
 func (ie *intEncoder) Encode() ([]byte, error) {
-	ie.bw.Flush()
-	buffWriter := buffer.NewBufferWriter(ie.buff)
-	buffWriter.PutUint64(ie.startTime)
-	buffWriter.PutUint16(uint16(ie.size))
-	return ie.buff.Bytes(), nil
+	if len(ie.points) < 1 {
+		return nil, ErrEncodeEmpty
+	}
+	// the first appended one is kept if several points share a timestamp
+	sort.SliceStable(ie.points, func(i, j int) bool {
+		return ie.points[i].ts > ie.points[j].ts
+	})
+	points := ie.points[:1]
+	for _, p := range ie.points[1:] {
+		if p.ts != points[len(points)-1].ts {
+			points = append(points, p)
+		}
+	}
+	ie.points = points
+	latest := points[0].ts
+	interval := uint64(ie.interval)
+	if !ie.aligned(interval) {
+		interval = 0
+	}
+	buff := &bytes.Buffer{}
+	bw := bit.NewWriter(buff)
+	values := NewXOREncoder(bw)
+	var slot uint64
+	for i, p := range points {
+		switch {
+		case interval > 0:
+			for next := (latest - p.ts) / interval; slot < next; slot++ {
+				bw.WriteBool(false)
+			}
+			bw.WriteBool(true)
+			slot++
+		case i > 0:
+			delta := points[i-1].ts - p.ts
+			n := bits.Len64(delta)
+			bw.WriteBits(uint64(n-1), 6)
+			bw.WriteBits(delta, n)
+		}
+		values.Write(p.val)
+	}
+	bw.Flush()
+	buffWriter := buffer.NewBufferWriter(buff)
+	buffWriter.PutUint64(interval)
+	buffWriter.PutUint64(latest)
+	buffWriter.PutUint32(uint32(len(points)))
+	return buffWriter.Bytes(), nil
+}
+
+// aligned checks whether the sorted points are aligned to the interval without too many empty slots in between
+func (ie *intEncoder) aligned(interval uint64) bool {
+	if interval == 0 {
+		return false
+	}
+	latest := ie.points[0].ts
+	for _, p := range ie.points {
+		if (latest-p.ts)%interval != 0 {
+			return false
+		}
+	}
+	slots := (latest-ie.points[len(ie.points)-1].ts)/interval + 1
+	return slots <= uint64(len(ie.points))*maxEmptySlots
 }
 
 func (ie *intEncoder) StartTime() uint64 {
 	return ie.startTime
 }
 
-var _ SeriesDecoder = (*intDecoder)(nil)
-
 type intDecoder struct {
-	fn        ParseInterval
-	size      int
-	interval  time.Duration
-	startTime uint64
-	num       int
-	area      []byte
+	size     int
+	interval uint64
+	latest   uint64
+	num      int
+	area     []byte
 }
 
-func NewIntDecoder(size int, fn ParseInterval) SeriesDecoder {
+// NewIntDecoder creates a decoder of the chunks encoded by NewIntEncoder
+func NewIntDecoder(size int) SeriesDecoder {
 	return &intDecoder{
-		fn:   fn,
 		size: size,
 	}
 }
 
-func (i intDecoder) Decode(key, data []byte) error {
-	i.interval = i.fn(key)
-	i.startTime = binary.LittleEndian.Uint64(data[len(data)-10 : len(data)-2])
-	i.num = int(binary.LittleEndian.Uint16(data[len(data)-2:]))
-	i.area = data[:len(data)-10]
+func (i *intDecoder) Decode(_, data []byte) error {
+	if len(data) < intTailLen {
+		return ErrInvalidValue
+	}
+	tail := data[len(data)-intTailLen:]
+	i.interval = binary.LittleEndian.Uint64(tail[:8])
+	i.latest = binary.LittleEndian.Uint64(tail[8:16])
+	i.num = int(binary.LittleEndian.Uint32(tail[16:]))
+	i.area = data[:len(data)-intTailLen]
 	return nil
 }
 
-func (i intDecoder) Len() int {
+func (i *intDecoder) Len() int {
 	return i.num
 }
 
-func (i intDecoder) IsFull() bool {
+func (i *intDecoder) IsFull() bool {
 	return i.num >= i.size
 }
 
-func (i intDecoder) Get(ts uint64) ([]byte, error) {
-	for iter := i.Iterator(); iter.Next(); {
+func (i *intDecoder) Get(ts uint64) ([]byte, error) {
+	iter := i.Iterator()
+	for iter.Next() {
 		if iter.Time() == ts {
 			return iter.Val(), nil
 		}
+		// the points are iterated from the latest one
+		if iter.Time() < ts {
+			break
+		}
+	}
+	if iter.Error() != nil {
+		return nil, iter.Error()
 	}
-	return nil, nil
+	return nil, fmt.Errorf("%d doesn't exist", ts)
 }
 
-func (i intDecoder) Iterator() SeriesIterator {
+func (i *intDecoder) Iterator() SeriesIterator {
 	br := bit.NewReader(bytes.NewReader(i.area))
 	return &intIterator{
-		startTime: i.startTime,
-		interval:  int(i.interval),
-		br:        br,
-		values:    NewXORDecoder(br),
+		latest:   i.latest,
+		interval: i.interval,
+		num:      i.num,
+		br:       br,
+		values:   NewXORDecoder(br),
 	}
 }
 
 var _ SeriesIterator = (*intIterator)(nil)
 
+// intIterator iterates the points from the latest one
 type intIterator struct {
-	startTime uint64
-	interval  int
-	br        *bit.Reader
-	values    *XORDecoder
+	latest   uint64
+	interval uint64
+	num      int
+	br       *bit.Reader
+	values   *XORDecoder
 
 	currVal  uint64
 	currTime uint64
+	slot     uint64
 	index    int
 	err      error
 }
 
 func (i *intIterator) Next() bool {
-	var b bool
-	b, i.err = i.br.ReadBool()
-	if i.err != nil {
+	if i.err != nil || i.index >= i.num {
 		return false
 	}
-	if b {
-		if i.values.Next() {
-			i.currVal = i.values.Value()
-		}
+	if !i.nextTime() {
+		return false
+	}
+	if !i.values.Next() {
+		i.err = i.values.Err()
+		return false
 	}
-	i.currVal = 0
-	i.currTime = i.startTime + uint64(i.interval*i.index)
+	i.currVal = i.values.Value()
 	i.index++
 	return true
 }
 
+func (i *intIterator) nextTime() bool {
+	if i.interval > 0 {
+		for {
+			var b bool
+			if b, i.err = i.br.ReadBool(); i.err != nil {
+				return false
+			}
+			i.slot++
+			if b {
+				break
+			}
+		}
+		i.currTime = i.latest - (i.slot-1)*i.interval
+		return true
+	}
+	if i.index == 0 {
+		i.currTime = i.latest
+		return true
+	}
+	var n, delta uint64
+	if n, i.err = i.br.ReadBits(6); i.err != nil {
+		return false
+	}
+	if delta, i.err = i.br.ReadBits(int(n) + 1); i.err != nil {
+		return false
+	}
+	i.currTime -= delta
+	return true
+}
+
 func (i *intIterator) Val() []byte {
 	return convert.Uint64ToBytes(i.currVal)
 }
@@ -0,0 +1,150 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/pkg/convert"
+)
+
+func TestInt(t *testing.T) {
+	minute := uint64(time.Minute)
+	base := uint64(time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	type point struct {
+		ts  uint64
+		val int64
+	}
+	tests := []struct {
+		name     string
+		interval time.Duration
+		points   []point
+		// want is in the descending order of the timestamps
+		want []point
+	}{
+		{
+			name:     "aligned",
+			interval: time.Minute,
+			points:   []point{{base, 1}, {base + minute, 2}, {base + 4*minute, -3}, {base + 5*minute, 1 << 40}},
+			want:     []point{{base + 5*minute, 1 << 40}, {base + 4*minute, -3}, {base + minute, 2}, {base, 1}},
+		},
+		{
+			name:     "descending",
+			interval: time.Minute,
+			points:   []point{{base + 2*minute, 7}, {base + minute, 7}, {base, 8}},
+			want:     []point{{base + 2*minute, 7}, {base + minute, 7}, {base, 8}},
+		},
+		{
+			name:     "unaligned",
+			interval: time.Minute,
+			points:   []point{{base, 10}, {base + 1, 20}, {base + minute + 7, 30}},
+			want:     []point{{base + minute + 7, 30}, {base + 1, 20}, {base, 10}},
+		},
+		{
+			name:     "sparse",
+			interval: time.Second,
+			points:   []point{{base, 10}, {base + 24*60*minute, 20}},
+			want:     []point{{base + 24*60*minute, 20}, {base, 10}},
+		},
+		{
+			name:   "no interval",
+			points: []point{{base + 3, 0}, {base, -1}},
+			want:   []point{{base + 3, 0}, {base, -1}},
+		},
+		{
+			name:     "duplicated",
+			interval: time.Minute,
+			points:   []point{{base + minute, 1}, {base + minute, 2}, {base, 3}},
+			want:     []point{{base + minute, 1}, {base, 3}},
+		},
+		{
+			name:   "single",
+			points: []point{{base, 42}},
+			want:   []point{{base, 42}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			tester := assert.New(t)
+			encoder := NewIntEncoderPool(len(tt.points), func([]byte) time.Duration {
+				return tt.interval
+			}).Get([]byte("key"))
+			for _, p := range tt.points {
+				encoder.Append(p.ts, convert.Int64ToBytes(p.val))
+			}
+			tester.True(encoder.IsFull())
+			tester.Equal(base, encoder.StartTime())
+			data, err := encoder.Encode()
+			req.NoError(err)
+
+			decoder := NewIntDecoderPool(len(tt.points)).Get([]byte("key"))
+			req.NoError(decoder.Decode([]byte("key"), data))
+			tester.Equal(len(tt.want), decoder.Len())
+			var got []point
+			iter := decoder.Iterator()
+			for iter.Next() {
+				got = append(got, point{ts: iter.Time(), val: convert.BytesToInt64(iter.Val())})
+			}
+			req.NoError(iter.Error())
+			tester.Equal(tt.want, got)
+			for _, p := range tt.want {
+				val, errGet := decoder.Get(p.ts)
+				tester.NoError(errGet)
+				tester.Equal(p.val, convert.BytesToInt64(val))
+			}
+			_, err = decoder.Get(base + 1<<40)
+			tester.Error(err)
+		})
+	}
+}
+
+func TestInt_Empty(t *testing.T) {
+	encoder := NewIntEncoder(10, nil)
+	encoder.Append(1, []byte("not an integer"))
+	_, err := encoder.Encode()
+	assert.ErrorIs(t, err, ErrEncodeEmpty)
+	assert.ErrorIs(t, NewIntDecoder(10).Decode(nil, []byte{1, 2, 3}), ErrInvalidValue)
+}
+
+func TestSelectivePool(t *testing.T) {
+	req := require.New(t)
+	selector := func(key []byte) bool {
+		return string(key) == "int"
+	}
+	encoderPool := NewSelectiveEncoderPool(selector, NewIntEncoderPool(10, nil), NewPlainEncoderPool(1024))
+	decoderPool := NewSelectiveDecoderPool(selector, NewIntDecoderPool(10), NewPlainDecoderPool(1024))
+	for _, key := range []string{"int", "plain"} {
+		encoder := encoderPool.Get([]byte(key))
+		encoder.Append(2, convert.Int64ToBytes(-2))
+		encoder.Append(1, convert.Int64ToBytes(1))
+		data, err := encoder.Encode()
+		req.NoError(err)
+		encoderPool.Put(encoder)
+		decoder := decoderPool.Get([]byte(key))
+		req.NoError(decoder.Decode([]byte(key), data))
+		val, err := decoder.Get(2)
+		req.NoError(err)
+		req.Equal(int64(-2), convert.BytesToInt64(val), key)
+		decoderPool.Put(decoder)
+	}
+}
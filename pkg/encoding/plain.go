@@ -149,7 +149,7 @@ func (t *plainEncoder) Encode() ([]byte, error) {
 	l := len(data)
 	dst := make([]byte, 0, compressBound(l))
 	dst = zstdEncoder.EncodeAll(data, dst)
-	result := buffer.NewBufferWriter(bytes.NewBuffer(make([]byte, len(dst)+2)))
+	result := buffer.NewBufferWriter(bytes.NewBuffer(make([]byte, 0, len(dst)+2)))
 	result.Write(dst)
 	result.PutUint16(uint16(l))
 	return result.Bytes(), nil
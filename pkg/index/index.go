@@ -25,6 +25,7 @@ import (
 
 	"github.com/apache/skywalking-banyandb/api/common"
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
+	"github.com/apache/skywalking-banyandb/banyand/kv"
 	"github.com/apache/skywalking-banyandb/pkg/convert"
 	"github.com/apache/skywalking-banyandb/pkg/index/metadata"
 	"github.com/apache/skywalking-banyandb/pkg/index/posting"
@@ -171,8 +172,22 @@ type Searcher interface {
 	Range(fieldKey FieldKey, opts RangeOpts) (list posting.List, err error)
 }
 
+// Batch collects fields and commits them to the store together
+type Batch interface {
+	Writer
+	Commit() error
+}
+
 type Store interface {
 	io.Closer
 	Writer
 	Searcher
+	// Snapshot writes a point-in-time copy of the store into a directory
+	Snapshot(dir string) error
+	NewBatch() Batch
+	// DeleteSeries drops the fields of the series, the compaction reclaims their space
+	DeleteSeries(seriesID common.SeriesID) error
+	// Stats returns the statistics of the store, whose KeyCount is the number of the indexed terms
+	Stats() kv.Stats
+	kv.Compactor
 }
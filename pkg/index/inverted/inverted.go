@@ -47,17 +47,30 @@ type store struct {
 type StoreOpts struct {
 	Path   string
 	Logger *logger.Logger
+	// ReadOnly opens the store in the read-only mode, whose writes fail
+	ReadOnly bool
+	// InMemory keeps the store in the memory, nothing is written into the Path
+	InMemory bool
 }
 
 func NewStore(opts StoreOpts) (index.Store, error) {
-	diskTable, err := kv.OpenIndexStore(0, opts.Path+"/table", kv.IndexWithLogger(opts.Logger))
+	options := []kv.IndexOptions{kv.IndexWithLogger(opts.Logger)}
+	if opts.ReadOnly {
+		options = append(options, kv.IndexWithReadOnly())
+	}
+	if opts.InMemory {
+		options = append(options, kv.IndexWithInMemory())
+	}
+	diskTable, err := kv.OpenIndexStore(0, opts.Path+"/table", options...)
 	if err != nil {
 		return nil, err
 	}
 	var md metadata.Term
 	if md, err = metadata.NewTerm(metadata.TermOpts{
-		Path:   opts.Path + "/tmd",
-		Logger: opts.Logger,
+		Path:     opts.Path + "/tmd",
+		Logger:   opts.Logger,
+		ReadOnly: opts.ReadOnly,
+		InMemory: opts.InMemory,
 	}); err != nil {
 		return nil, err
 	}
@@ -69,13 +82,69 @@ func NewStore(opts StoreOpts) (index.Store, error) {
 }
 
 func (s *store) Close() error {
-	return s.diskTable.Close()
+	var err error
+	if !s.memTable.isEmpty() {
+		err = s.Flush()
+	}
+	return multierr.Combine(err, s.diskTable.Close(), s.termMetadata.Close())
+}
+
+func (s *store) Snapshot(dir string) error {
+	if !s.memTable.isEmpty() {
+		if err := s.Flush(); err != nil {
+			return err
+		}
+	}
+	return multierr.Combine(s.diskTable.Snapshot(dir+"/table"), s.termMetadata.Snapshot(dir+"/tmd"))
+}
+
+// Stats flushes the memory table to take the terms in it into account
+func (s *store) Stats() kv.Stats {
+	if !s.memTable.isEmpty() {
+		_ = s.Flush()
+	}
+	stats := s.diskTable.Stats()
+	stats.DiskSize += s.termMetadata.Stats().DiskSize
+	return stats
+}
+
+// RequestCompaction flushes the memory table into the disk table to compact them together
+func (s *store) RequestCompaction() {
+	if !s.memTable.isEmpty() {
+		_ = s.Flush()
+	}
+	s.diskTable.RequestCompaction()
+}
+
+// DeleteSeries flushes the memory table to drop the fields in it along with the ones in the disk table
+func (s *store) DeleteSeries(seriesID common.SeriesID) error {
+	if !s.memTable.isEmpty() {
+		if err := s.Flush(); err != nil {
+			return err
+		}
+	}
+	return s.diskTable.DeletePrefix(seriesID.Marshal())
 }
 
 func (s *store) Write(field index.Field, chunkID common.ItemID) error {
 	return s.memTable.Write(field, chunkID)
 }
 
+// NewBatch returns a batch which writes fields into the memory table directly, since it never blocks on the disk
+func (s *store) NewBatch() index.Batch {
+	return &batch{store: s}
+}
+
+var _ index.Batch = (*batch)(nil)
+
+type batch struct {
+	*store
+}
+
+func (b *batch) Commit() error {
+	return nil
+}
+
 func (s *store) Flush() error {
 	s.rwMutex.Lock()
 	defer s.rwMutex.Unlock()
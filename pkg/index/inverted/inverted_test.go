@@ -63,6 +63,22 @@ func TestStore_MatchTerm_AfterFlush(t *testing.T) {
 	testcases.RunServiceName(t, s)
 }
 
+func TestStore_MatchTerm_InMemory(t *testing.T) {
+	tester := assert.New(t)
+	initLogger(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Logger:   logger.GetLogger("test"),
+		InMemory: true,
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	testcases.SetUp(tester, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunServiceName(t, s)
+}
+
 func TestStore_Iterator(t *testing.T) {
 	tester := assert.New(t)
 	path, fn := setUp(require.New(t))
@@ -96,6 +112,22 @@ func TestStore_Iterator_AfterFlush(t *testing.T) {
 	testcases.RunDuration(t, data, s)
 }
 
+func TestStore_Iterator_InMemory(t *testing.T) {
+	tester := assert.New(t)
+	initLogger(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Logger:   logger.GetLogger("test"),
+		InMemory: true,
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	data := testcases.SetUpDuration(tester, s)
+	tester.NoError(s.(*store).Flush())
+	testcases.RunDuration(t, data, s)
+}
+
 func TestStore_Iterator_Hybrid(t *testing.T) {
 	tester := assert.New(t)
 	path, fn := setUp(require.New(t))
@@ -134,10 +166,14 @@ func TestStore_Iterator_Hybrid(t *testing.T) {
 }
 
 func setUp(t *require.Assertions) (tempDir string, deferFunc func()) {
+	initLogger(t)
+	tempDir, deferFunc = test.Space(t)
+	return tempDir, deferFunc
+}
+
+func initLogger(t *require.Assertions) {
 	t.NoError(logger.Init(logger.Logging{
 		Env:   "dev",
 		Level: "debug",
 	}))
-	tempDir, deferFunc = test.Space(t)
-	return tempDir, deferFunc
 }
@@ -51,6 +51,12 @@ func (m *memTable) Write(field index.Field, itemID common.ItemID) error {
 	return m.fields.put(field, itemID)
 }
 
+func (m *memTable) isEmpty() bool {
+	m.fields.mutex.RLock()
+	defer m.fields.mutex.RUnlock()
+	return len(m.fields.lst) < 1
+}
+
 var _ index.FieldIterator = (*fIterator)(nil)
 
 type fIterator struct {
@@ -18,6 +18,8 @@
 package lsm
 
 import (
+	"go.uber.org/multierr"
+
 	"github.com/apache/skywalking-banyandb/api/common"
 	"github.com/apache/skywalking-banyandb/banyand/kv"
 	"github.com/apache/skywalking-banyandb/pkg/convert"
@@ -34,7 +36,25 @@ type store struct {
 }
 
 func (s *store) Close() error {
-	return s.lsm.Close()
+	return multierr.Combine(s.lsm.Close(), s.termMetadata.Close())
+}
+
+func (s *store) Snapshot(dir string) error {
+	return multierr.Combine(s.lsm.Snapshot(dir+"/lsm"), s.termMetadata.Snapshot(dir+"/tmd"))
+}
+
+func (s *store) Stats() kv.Stats {
+	stats := s.lsm.Stats()
+	stats.DiskSize += s.termMetadata.Stats().DiskSize
+	return stats
+}
+
+func (s *store) RequestCompaction() {
+	s.lsm.RequestCompaction()
+}
+
+func (s *store) DeleteSeries(seriesID common.SeriesID) error {
+	return s.lsm.DeletePrefix(seriesID.Marshal())
 }
 
 func (s *store) Write(field index.Field, itemID common.ItemID) error {
@@ -46,21 +66,62 @@ func (s *store) Write(field index.Field, itemID common.ItemID) error {
 	return s.lsm.PutWithVersion(f, convert.Uint64ToBytes(itemIDInt), itemIDInt)
 }
 
+func (s *store) NewBatch() index.Batch {
+	return &batch{
+		store: s,
+		lsm:   s.lsm.NewBatch(),
+	}
+}
+
+var _ index.Batch = (*batch)(nil)
+
+type batch struct {
+	store *store
+	lsm   kv.Batch
+}
+
+func (b *batch) Write(field index.Field, itemID common.ItemID) error {
+	f, err := field.Marshal(b.store.termMetadata)
+	if err != nil {
+		return err
+	}
+	itemIDInt := uint64(itemID)
+	b.lsm.Put(f, convert.Uint64ToBytes(itemIDInt), itemIDInt)
+	return nil
+}
+
+func (b *batch) Commit() error {
+	return b.lsm.Commit()
+}
+
 type StoreOpts struct {
 	Path   string
 	Logger *logger.Logger
+	// ReadOnly opens the store in the read-only mode, whose writes fail
+	ReadOnly bool
+	// InMemory keeps the store in the memory, nothing is written into the Path
+	InMemory bool
 }
 
 func NewStore(opts StoreOpts) (index.Store, error) {
 	var err error
 	var lsm kv.Store
-	if lsm, err = kv.OpenStore(0, opts.Path+"/lsm", kv.StoreWithLogger(opts.Logger)); err != nil {
+	options := []kv.StoreOptions{kv.StoreWithLogger(opts.Logger)}
+	if opts.ReadOnly {
+		options = append(options, kv.StoreWithReadOnly())
+	}
+	if opts.InMemory {
+		options = append(options, kv.StoreWithInMemory())
+	}
+	if lsm, err = kv.OpenStore(0, opts.Path+"/lsm", options...); err != nil {
 		return nil, err
 	}
 	var md metadata.Term
 	if md, err = metadata.NewTerm(metadata.TermOpts{
-		Path:   opts.Path + "/tmd",
-		Logger: opts.Logger,
+		Path:     opts.Path + "/tmd",
+		Logger:   opts.Logger,
+		ReadOnly: opts.ReadOnly,
+		InMemory: opts.InMemory,
 	}); err != nil {
 		return nil, err
 	}
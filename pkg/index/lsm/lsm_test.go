@@ -60,11 +60,45 @@ func TestStore_Iterator(t *testing.T) {
 	testcases.RunDuration(t, data, s)
 }
 
+func TestStore_MatchTerm_InMemory(t *testing.T) {
+	tester := assert.New(t)
+	initLogger(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Logger:   logger.GetLogger("test"),
+		InMemory: true,
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	testcases.SetUp(tester, s)
+	testcases.RunServiceName(t, s)
+}
+
+func TestStore_Iterator_InMemory(t *testing.T) {
+	tester := assert.New(t)
+	initLogger(require.New(t))
+	s, err := NewStore(StoreOpts{
+		Logger:   logger.GetLogger("test"),
+		InMemory: true,
+	})
+	tester.NoError(err)
+	defer func() {
+		tester.NoError(s.Close())
+	}()
+	data := testcases.SetUpDuration(tester, s)
+	testcases.RunDuration(t, data, s)
+}
+
 func setUp(t *require.Assertions) (tempDir string, deferFunc func()) {
+	initLogger(t)
+	tempDir, deferFunc = test.Space(t)
+	return tempDir, deferFunc
+}
+
+func initLogger(t *require.Assertions) {
 	t.NoError(logger.Init(logger.Logging{
 		Env:   "dev",
 		Level: "info",
 	}))
-	tempDir, deferFunc = test.Space(t)
-	return tempDir, deferFunc
 }
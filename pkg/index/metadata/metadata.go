@@ -18,6 +18,8 @@
 package metadata
 
 import (
+	"io"
+
 	"github.com/pkg/errors"
 
 	"github.com/apache/skywalking-banyandb/banyand/kv"
@@ -26,6 +28,9 @@ import (
 )
 
 type Term interface {
+	io.Closer
+	kv.Snapshotter
+	kv.StatsReporter
 	ID(term []byte) (id []byte, err error)
 	Literal(id []byte) (term []byte, err error)
 }
@@ -33,27 +38,43 @@ type Term interface {
 var _ Term = (*term)(nil)
 
 type term struct {
-	store kv.Store
+	store    kv.Store
+	readOnly bool
 }
 
 type TermOpts struct {
 	Path   string
 	Logger *logger.Logger
+	// ReadOnly opens the metadata in the read-only mode, the unknown terms aren't recorded
+	ReadOnly bool
+	// InMemory keeps the metadata in the memory, nothing is written into the Path
+	InMemory bool
 }
 
 func NewTerm(opts TermOpts) (Term, error) {
 	var store kv.Store
 	var err error
-	if store, err = kv.OpenStore(0, opts.Path, kv.StoreWithNamedLogger("term_metadata", opts.Logger)); err != nil {
+	options := []kv.StoreOptions{kv.StoreWithNamedLogger("term_metadata", opts.Logger)}
+	if opts.ReadOnly {
+		options = append(options, kv.StoreWithReadOnly())
+	}
+	if opts.InMemory {
+		options = append(options, kv.StoreWithInMemory())
+	}
+	if store, err = kv.OpenStore(0, opts.Path, options...); err != nil {
 		return nil, err
 	}
 	return &term{
-		store: store,
+		store:    store,
+		readOnly: opts.ReadOnly,
 	}, nil
 }
 
 func (t *term) ID(term []byte) (id []byte, err error) {
 	id = convert.Uint64ToBytes(convert.Hash(term))
+	if t.readOnly {
+		return id, nil
+	}
 	_, err = t.store.Get(id)
 	if errors.Is(err, kv.ErrKeyNotFound) {
 		return id, t.store.Put(id, term)
@@ -64,3 +85,15 @@ func (t *term) ID(term []byte) (id []byte, err error) {
 func (t *term) Literal(id []byte) (term []byte, err error) {
 	return t.store.Get(id)
 }
+
+func (t *term) Snapshot(dir string) error {
+	return t.store.Snapshot(dir)
+}
+
+func (t *term) Stats() kv.Stats {
+	return t.store.Stats()
+}
+
+func (t *term) Close() error {
+	return t.store.Close()
+}
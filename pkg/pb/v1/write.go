@@ -35,6 +35,11 @@ const strDelimiter = "\n"
 
 var ErrUnsupportedTagForIndexField = errors.New("the tag type(for example, null) can not be as the index field value")
 
+// NullTagValue is the value of the absent tags, which should not be modified
+var NullTagValue = &modelv1.TagValue{
+	Value: &modelv1.TagValue_Null{},
+}
+
 func MarshalIndexFieldValue(tagValue *modelv1.TagValue) ([]byte, error) {
 	switch x := tagValue.GetValue().(type) {
 	case *modelv1.TagValue_Str:
@@ -19,6 +19,7 @@ package logical
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -150,7 +151,11 @@ func (a *Analyzer) Analyze(_ context.Context, criteria *streamv1.QueryRequest, m
 //     i.e. they are top-level sharding keys. For example, for the current skywalking's schema,
 //     we use service_id + service_instance_id + state as the compound sharding keys.
 func parseFields(criteria *streamv1.QueryRequest, metadata *commonv1.Metadata, s Schema) (UnresolvedPlan, error) {
-	timeRange := criteria.GetTimeRange()
+	// the zero time means the time range is absent
+	var startTime, endTime time.Time
+	if timeRange := criteria.GetTimeRange(); timeRange != nil {
+		startTime, endTime = timeRange.GetBegin().AsTime(), timeRange.GetEnd().AsTime()
+	}
 
 	projTags := make([][]*Tag, len(criteria.GetProjection().GetTagFamilies()))
 	for i, tagFamily := range criteria.GetProjection().GetTagFamilies() {
@@ -212,6 +217,6 @@ func parseFields(criteria *streamv1.QueryRequest, metadata *commonv1.Metadata, s
 		}
 	}
 
-	return IndexScan(timeRange.GetBegin().AsTime(), timeRange.GetEnd().AsTime(), metadata,
+	return IndexScan(startTime, endTime, metadata,
 		tagExprs, entity, nil, projTags...), nil
 }
@@ -180,7 +180,7 @@ func TestAnalyzer_TraceIDQuery(t *testing.T) {
 	assert.NoError(err)
 	assert.NotNil(plan)
 	correctPlan, err := logical.Limit(
-		logical.Offset(logical.IndexScan(time.Now(), time.Now(), metadata, []logical.Expr{
+		logical.Offset(logical.IndexScan(time.Time{}, time.Time{}, metadata, []logical.Expr{
 			logical.Eq(logical.NewSearchableFieldRef("trace_id"), logical.Str("123")),
 		}, nil, nil),
 			0),
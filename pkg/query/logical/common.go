@@ -20,6 +20,8 @@ package logical
 import (
 	"bytes"
 
+	"github.com/pkg/errors"
+
 	modelv1 "github.com/apache/skywalking-banyandb/api/proto/banyandb/model/v1"
 	"github.com/apache/skywalking-banyandb/banyand/tsdb"
 	"github.com/apache/skywalking-banyandb/pkg/query/executor"
@@ -47,20 +49,15 @@ func createComparator(sortDirection modelv1.Sort) comparator {
 func projectItem(ec executor.ExecutionContext, item tsdb.Item, projectionFieldRefs [][]*FieldRef) ([]*modelv1.TagFamily, error) {
 	tagFamily := make([]*modelv1.TagFamily, len(projectionFieldRefs))
 	for i, refs := range projectionFieldRefs {
-		tags := make([]*modelv1.Tag, len(refs))
-		familyName := refs[0].tag.GetFamilyName()
-		parsedTagFamily, err := ec.ParseTagFamily(familyName, item)
-		if err != nil {
-			return nil, err
-		}
+		tagNames := make([]string, len(refs))
 		for j, ref := range refs {
-			tags[j] = parsedTagFamily.GetTags()[ref.Spec.TagIdx]
+			tagNames[j] = ref.tag.GetTagName()
 		}
-
-		tagFamily[i] = &modelv1.TagFamily{
-			Name: familyName,
-			Tags: tags,
+		parsedTagFamily, err := ec.ProjectTagFamily(refs[0].tag.GetFamilyName(), tagNames, item)
+		if err != nil {
+			return nil, err
 		}
+		tagFamily[i] = parsedTagFamily
 	}
 
 	return tagFamily, nil
@@ -76,12 +73,16 @@ func executeForShard(series tsdb.SeriesList, timeRange tsdb.TimeRange,
 	for _, seriesFound := range series {
 		itersInSeries, err := func() ([]tsdb.Iterator, error) {
 			sp, errInner := seriesFound.Span(timeRange)
-			defer func(sp tsdb.SeriesSpan) {
-				_ = sp.Close()
-			}(sp)
+			// the series has no data in the time range
+			if errors.Is(errInner, tsdb.ErrEmptySeriesSpan) {
+				return nil, nil
+			}
 			if errInner != nil {
 				return nil, errInner
 			}
+			defer func(sp tsdb.SeriesSpan) {
+				_ = sp.Close()
+			}(sp)
 			b := sp.SeekerBuilder()
 			for _, builder := range builders {
 				builder(b)
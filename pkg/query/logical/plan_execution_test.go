@@ -143,11 +143,39 @@ func TestPlanExecution_Offset(t *testing.T) {
 	}
 }
 
+func TestPlanExecution_EmptyTimeRange(t *testing.T) {
+	tester := require.New(t)
+	streamSvc, metaService, deferFunc := setup(tester)
+	defer deferFunc()
+	baseTs := setupQueryData(t, "multiple_shards.json", streamSvc)
+
+	metadata := &commonv1.Metadata{
+		Name:  "sw",
+		Group: "default",
+	}
+
+	// no data is written in the time range
+	sT, eT := baseTs.Add(-10*24*time.Hour), baseTs.Add(-10*24*time.Hour+time.Hour)
+
+	analyzer, err := logical.CreateAnalyzerFromMetaService(metaService)
+	tester.NoError(err)
+	schema, err := analyzer.BuildStreamSchema(context.TODO(), metadata)
+	tester.NoError(err)
+
+	plan, err := logical.IndexScan(sT, eT, metadata, nil, tsdb.Entity{tsdb.AnyEntry, tsdb.AnyEntry, tsdb.AnyEntry}, nil).Analyze(schema)
+	tester.NoError(err)
+	tester.NotNil(plan)
+
+	entities, err := plan.Execute(streamSvc)
+	tester.NoError(err)
+	tester.Empty(entities)
+}
+
 func TestPlanExecution_TraceIDFetch(t *testing.T) {
 	tester := require.New(t)
 	streamSvc, metaService, deferFunc := setup(tester)
 	defer deferFunc()
-	_ = setupQueryData(t, "multiple_shards.json", streamSvc)
+	baseTs := setupQueryData(t, "multiple_shards.json", streamSvc)
 
 	m := &commonv1.Metadata{
 		Name:  "sw",
@@ -158,26 +186,43 @@ func TestPlanExecution_TraceIDFetch(t *testing.T) {
 	tester.NoError(err)
 	tester.NotNil(analyzer)
 
+	sT, eT := baseTs, baseTs.Add(1*time.Hour)
+
 	tests := []struct {
 		name       string
 		traceID    string
+		startTime  time.Time
+		endTime    time.Time
 		wantLength int
 	}{
 		{
 			name:       "traceID = 1",
 			traceID:    "1",
+			startTime:  sT,
+			endTime:    eT,
 			wantLength: 1,
 		},
 		{
 			name:       "traceID = 2",
 			traceID:    "2",
+			startTime:  sT,
+			endTime:    eT,
 			wantLength: 1,
 		},
 		{
 			name:       "traceID = 3",
 			traceID:    "3",
+			startTime:  sT,
+			endTime:    eT,
 			wantLength: 1,
 		},
+		{
+			name:       "traceID = 1 before the time range",
+			traceID:    "1",
+			startTime:  sT.Add(-2 * time.Hour),
+			endTime:    sT.Add(-1 * time.Hour),
+			wantLength: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -186,7 +231,7 @@ func TestPlanExecution_TraceIDFetch(t *testing.T) {
 			s, err := analyzer.BuildStreamSchema(context.TODO(), m)
 			tester.NoError(err)
 
-			p, err := logical.GlobalIndexScan(m, []logical.Expr{
+			p, err := logical.GlobalIndexScan(tt.startTime, tt.endTime, m, []logical.Expr{
 				logical.Eq(logical.NewFieldRef("searchable", "trace_id"), logical.Str(tt.traceID)),
 			}, logical.NewTags("searchable", "trace_id")).Analyze(s)
 			tester.NoError(err)
@@ -39,6 +39,7 @@ var _ Plan = (*globalIndexScan)(nil)
 type globalIndexScan struct {
 	schema              Schema
 	metadata            *commonv1.Metadata
+	timeRange           tsdb.TimeRange
 	globalIndexRule     *databasev1.IndexRule
 	expr                Expr
 	projectionFieldRefs [][]*FieldRef
@@ -46,11 +47,11 @@ type globalIndexScan struct {
 
 func (t *globalIndexScan) String() string {
 	if len(t.projectionFieldRefs) == 0 {
-		return fmt.Sprintf("GlobalIndexScan: Metadata{group=%s,name=%s},condition=%s; projection=None",
-			t.metadata.GetGroup(), t.metadata.GetName(), t.expr.String())
+		return fmt.Sprintf("GlobalIndexScan: startTime=%d,endTime=%d,Metadata{group=%s,name=%s},condition=%s; projection=None",
+			t.timeRange.Start.Unix(), t.timeRange.End.Unix(), t.metadata.GetGroup(), t.metadata.GetName(), t.expr.String())
 	}
-	return fmt.Sprintf("GlobalIndexScan: Metadata{group=%s,name=%s},conditions=%s; projection=%s",
-		t.metadata.GetGroup(), t.metadata.GetName(),
+	return fmt.Sprintf("GlobalIndexScan: startTime=%d,endTime=%d,Metadata{group=%s,name=%s},conditions=%s; projection=%s",
+		t.timeRange.Start.Unix(), t.timeRange.End.Unix(), t.metadata.GetGroup(), t.metadata.GetName(),
 		t.expr.String(), formatExpr(", ", t.projectionFieldRefs...))
 }
 
@@ -73,6 +74,8 @@ func (t *globalIndexScan) Equal(plan Plan) bool {
 	other := plan.(*globalIndexScan)
 	return t.metadata.GetGroup() == other.metadata.GetGroup() &&
 		t.metadata.GetName() == other.metadata.GetName() &&
+		t.timeRange.Start.UnixNano() == other.timeRange.Start.UnixNano() &&
+		t.timeRange.End.UnixNano() == other.timeRange.End.UnixNano() &&
 		cmp.Equal(t.projectionFieldRefs, other.projectionFieldRefs) &&
 		cmp.Equal(t.schema, other.schema) &&
 		cmp.Equal(t.globalIndexRule.GetMetadata().GetId(), other.globalIndexRule.GetMetadata().GetId()) &&
@@ -102,7 +105,7 @@ func (t *globalIndexScan) executeForShard(ec executor.ExecutionContext, shard ts
 			IndexRuleID: t.globalIndexRule.GetMetadata().GetId(),
 		},
 		Term: t.expr.(*binaryExpr).r.(LiteralExpr).Bytes()[0],
-	})
+	}, t.timeRange)
 	if err != nil || len(itemIDs) < 1 {
 		return elementsInShard, nil
 	}
@@ -90,10 +90,16 @@ func (uis *unresolvedIndexScan) Analyze(s Schema) (Plan, error) {
 		if len(globalConditions)/2 > 1 {
 			return nil, ErrMultipleGlobalIndexes
 		}
+		timeRange := tsdb.NewTimeRange(uis.startTime, uis.endTime)
+		// the global index is looked up in all the segments if the time range is absent
+		if uis.startTime.IsZero() && uis.endTime.IsZero() {
+			timeRange = tsdb.AnyTimeRange
+		}
 		return &globalIndexScan{
 			schema:              s,
 			projectionFieldRefs: projFieldsRefs,
 			metadata:            uis.metadata,
+			timeRange:           timeRange,
 			globalIndexRule:     globalConditions[0].(*databasev1.IndexRule),
 			expr:                globalConditions[1].(Expr),
 		}, nil
@@ -258,8 +264,10 @@ func IndexScan(startTime, endTime time.Time, metadata *commonv1.Metadata, condit
 }
 
 // GlobalIndexScan is a short-hand method for composing a globalIndexScan plan
-func GlobalIndexScan(metadata *commonv1.Metadata, conditions []Expr, projection ...[]*Tag) UnresolvedPlan {
+func GlobalIndexScan(startTime, endTime time.Time, metadata *commonv1.Metadata, conditions []Expr, projection ...[]*Tag) UnresolvedPlan {
 	return &unresolvedIndexScan{
+		startTime:        startTime,
+		endTime:          endTime,
 		metadata:         metadata,
 		conditions:       conditions,
 		projectionFields: projection,
@@ -0,0 +1,330 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package wal implements a write-ahead log, whose entries are kept until they are marked as done.
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+const (
+	segmentPrefix = "wal-"
+	segmentSuffix = ".log"
+	headerSize    = 4 + 4 + 1 + 8
+
+	defaultSegmentSize = 64 << 20
+)
+
+const (
+	recordEntry byte = iota
+	recordDone
+)
+
+var (
+	ErrClosed          = errors.New("the log is closed")
+	ErrRecordCorrupted = errors.New("the record is corrupted")
+)
+
+type Options struct {
+	// SegmentSize is the size of a segment file to roll over to a new one
+	SegmentSize int64
+	// SyncWrites flushes every entry to the disk before Append returns
+	SyncWrites bool
+}
+
+// Log appends entries to segment files. A segment file is removed once all entries in it
+// and in the previous segments are done.
+type Log struct {
+	dir  string
+	opts Options
+
+	mu       sync.Mutex
+	segments []*segment
+	active   *os.File
+	size     int64
+	nextSeq  uint64
+	owners   map[uint64]*segment
+	pending  map[uint64][]byte
+	closed   bool
+}
+
+type segment struct {
+	firstSeq uint64
+	path     string
+	pending  int
+}
+
+// Open loads the segment files in the dir, the entries which aren't done could be replayed by Replay
+func Open(dir string, opts Options) (*Log, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = defaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", dir)
+	}
+	l := &Log{
+		dir:     dir,
+		opts:    opts,
+		nextSeq: 1,
+		owners:  make(map[uint64]*segment),
+		pending: make(map[uint64][]byte),
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", dir)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		firstSeq, errParse := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), 10, 64)
+		if errParse != nil {
+			return nil, errors.Wrapf(errParse, "invalid segment file: %s", name)
+		}
+		l.segments = append(l.segments, &segment{firstSeq: firstSeq, path: filepath.Join(dir, name)})
+	}
+	sort.Slice(l.segments, func(i, j int) bool {
+		return l.segments[i].firstSeq < l.segments[j].firstSeq
+	})
+	for _, s := range l.segments {
+		// an empty segment keeps the next sequence in its name
+		if s.firstSeq > l.nextSeq {
+			l.nextSeq = s.firstSeq
+		}
+		if err = l.load(s); err != nil {
+			return nil, err
+		}
+	}
+	// a new segment is always created to avoid appending after a torn record.
+	// The last segment without any entry takes the next sequence, which is skipped to name the new one.
+	if n := len(l.segments); n > 0 && l.segments[n-1].firstSeq == l.nextSeq {
+		l.nextSeq++
+	}
+	if err = l.rollover(); err != nil {
+		return nil, err
+	}
+	l.purge()
+	return l, nil
+}
+
+func (l *Log) load(s *segment) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", s.path)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		typ, seq, data, errRead := readRecord(r)
+		// the records after a torn one written by a crash are dropped
+		if errRead != nil {
+			return nil
+		}
+		if seq >= l.nextSeq {
+			l.nextSeq = seq + 1
+		}
+		switch typ {
+		case recordEntry:
+			l.pending[seq] = data
+			l.owners[seq] = s
+			s.pending++
+		case recordDone:
+			l.markDone(seq)
+		}
+	}
+}
+
+// Append writes the data as a new entry and returns its sequence
+func (l *Log) Append(data []byte) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, ErrClosed
+	}
+	if l.size >= l.opts.SegmentSize {
+		if err := l.rollover(); err != nil {
+			return 0, err
+		}
+	}
+	seq := l.nextSeq
+	if err := l.write(recordEntry, seq, data); err != nil {
+		return 0, err
+	}
+	if l.opts.SyncWrites {
+		if err := l.active.Sync(); err != nil {
+			return 0, errors.Wrap(err, "failed to sync the log")
+		}
+	}
+	l.nextSeq++
+	s := l.segments[len(l.segments)-1]
+	l.owners[seq] = s
+	s.pending++
+	return seq, nil
+}
+
+// Done marks the entry is applied, which will never be replayed
+func (l *Log) Done(seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if _, ok := l.owners[seq]; !ok {
+		return nil
+	}
+	if err := l.write(recordDone, seq, nil); err != nil {
+		return err
+	}
+	l.markDone(seq)
+	l.purge()
+	return nil
+}
+
+// Replay invokes the fn with the entries which aren't done in the order of their sequences.
+// An entry is marked as done if the fn succeeds.
+func (l *Log) Replay(fn func(seq uint64, data []byte) error) error {
+	l.mu.Lock()
+	seqs := make([]uint64, 0, len(l.pending))
+	for seq := range l.pending {
+		seqs = append(seqs, seq)
+	}
+	l.mu.Unlock()
+	sort.Slice(seqs, func(i, j int) bool {
+		return seqs[i] < seqs[j]
+	})
+	var err error
+	for _, seq := range seqs {
+		l.mu.Lock()
+		data, ok := l.pending[seq]
+		l.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if errReplay := fn(seq, data); errReplay != nil {
+			err = multierr.Append(err, errors.WithMessagef(errReplay, "failed to replay the entry %d", seq))
+			continue
+		}
+		err = multierr.Append(err, l.Done(seq))
+	}
+	return err
+}
+
+// Pending returns the number of the entries which aren't done
+func (l *Log) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.owners)
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.active.Close()
+}
+
+func (l *Log) markDone(seq uint64) {
+	s, ok := l.owners[seq]
+	if !ok {
+		return
+	}
+	delete(l.owners, seq)
+	delete(l.pending, seq)
+	s.pending--
+}
+
+// purge removes the leading segments whose entries are all done, except the active one
+func (l *Log) purge() {
+	for len(l.segments) > 1 && l.segments[0].pending == 0 {
+		_ = os.Remove(l.segments[0].path)
+		l.segments = l.segments[1:]
+	}
+}
+
+func (l *Log) rollover() error {
+	path := filepath.Join(l.dir, fmt.Sprintf("%s%020d%s", segmentPrefix, l.nextSeq, segmentSuffix))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the segment %s", path)
+	}
+	if l.active != nil {
+		if err = l.active.Close(); err != nil {
+			_ = f.Close()
+			return errors.Wrap(err, "failed to close the active segment")
+		}
+	}
+	l.active = f
+	l.size = 0
+	l.segments = append(l.segments, &segment{firstSeq: l.nextSeq, path: path})
+	l.purge()
+	return nil
+}
+
+func (l *Log) write(typ byte, seq uint64, data []byte) error {
+	buf := make([]byte, headerSize+len(data))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(len(data)))
+	buf[8] = typ
+	binary.BigEndian.PutUint64(buf[9:headerSize], seq)
+	copy(buf[headerSize:], data)
+	binary.BigEndian.PutUint32(buf[0:4], crc32.ChecksumIEEE(buf[4:]))
+	n, err := l.active.Write(buf)
+	l.size += int64(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to write the log")
+	}
+	return nil
+}
+
+func readRecord(r io.Reader) (typ byte, seq uint64, data []byte, err error) {
+	header := make([]byte, headerSize)
+	if _, err = io.ReadFull(r, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = ErrRecordCorrupted
+		}
+		return 0, 0, nil, err
+	}
+	data = make([]byte, binary.BigEndian.Uint32(header[4:8]))
+	if _, err = io.ReadFull(r, data); err != nil {
+		return 0, 0, nil, ErrRecordCorrupted
+	}
+	h := crc32.NewIEEE()
+	_, _ = h.Write(header[4:])
+	_, _ = h.Write(data)
+	if h.Sum32() != binary.BigEndian.Uint32(header[0:4]) {
+		return 0, 0, nil, ErrRecordCorrupted
+	}
+	return header[8], binary.BigEndian.Uint64(header[9:]), data, nil
+}
@@ -0,0 +1,146 @@
+// Licensed to Apache Software Foundation (ASF) under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Apache Software Foundation (ASF) licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package wal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/apache/skywalking-banyandb/pkg/test"
+)
+
+func TestReplay(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	dir, deferFunc := test.Space(req)
+	defer deferFunc()
+	l, err := Open(dir, Options{SyncWrites: true})
+	req.NoError(err)
+	var seqs []uint64
+	for i := 0; i < 5; i++ {
+		seq, errAppend := l.Append([]byte(fmt.Sprintf("entry-%d", i)))
+		req.NoError(errAppend)
+		seqs = append(seqs, seq)
+	}
+	req.NoError(l.Done(seqs[0]))
+	req.NoError(l.Done(seqs[3]))
+	tester.Equal(3, l.Pending())
+	req.NoError(l.Close())
+
+	l, err = Open(dir, Options{})
+	req.NoError(err)
+	tester.Equal(3, l.Pending())
+	var replayed []string
+	req.NoError(l.Replay(func(_ uint64, data []byte) error {
+		replayed = append(replayed, string(data))
+		return nil
+	}))
+	tester.Equal([]string{"entry-1", "entry-2", "entry-4"}, replayed)
+	tester.Equal(0, l.Pending())
+	seq, err := l.Append([]byte("entry-5"))
+	req.NoError(err)
+	tester.Greater(seq, seqs[4])
+	req.NoError(l.Close())
+
+	l, err = Open(dir, Options{})
+	req.NoError(err)
+	replayed = replayed[:0]
+	req.NoError(l.Replay(func(_ uint64, data []byte) error {
+		replayed = append(replayed, string(data))
+		return nil
+	}))
+	tester.Equal([]string{"entry-5"}, replayed)
+	req.NoError(l.Close())
+
+	// the sequences keep growing even if all entries are done and their segments are removed
+	for i := 0; i < 2; i++ {
+		l, err = Open(dir, Options{})
+		req.NoError(err)
+		req.NoError(l.Close())
+	}
+	l, err = Open(dir, Options{})
+	req.NoError(err)
+	defer l.Close()
+	tester.Equal(0, l.Pending())
+	next, err := l.Append([]byte("entry-6"))
+	req.NoError(err)
+	tester.Greater(next, seq)
+}
+
+func TestTornRecord(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	dir, deferFunc := test.Space(req)
+	defer deferFunc()
+	l, err := Open(dir, Options{})
+	req.NoError(err)
+	_, err = l.Append([]byte("entry-0"))
+	req.NoError(err)
+	_, err = l.Append([]byte("entry-1"))
+	req.NoError(err)
+	req.NoError(l.Close())
+	files, err := filepath.Glob(filepath.Join(dir, segmentPrefix+"*"))
+	req.NoError(err)
+	req.Len(files, 1)
+	info, err := os.Stat(files[0])
+	req.NoError(err)
+	req.NoError(os.Truncate(files[0], info.Size()-1))
+
+	l, err = Open(dir, Options{})
+	req.NoError(err)
+	defer l.Close()
+	var replayed []string
+	req.NoError(l.Replay(func(_ uint64, data []byte) error {
+		replayed = append(replayed, string(data))
+		return nil
+	}))
+	tester.Equal([]string{"entry-0"}, replayed)
+}
+
+func TestPurge(t *testing.T) {
+	req := require.New(t)
+	tester := assert.New(t)
+	dir, deferFunc := test.Space(req)
+	defer deferFunc()
+	l, err := Open(dir, Options{SegmentSize: 1})
+	req.NoError(err)
+	defer l.Close()
+	var seqs []uint64
+	for i := 0; i < 3; i++ {
+		seq, errAppend := l.Append([]byte("entry"))
+		req.NoError(errAppend)
+		seqs = append(seqs, seq)
+	}
+	countSegments := func() int {
+		files, errGlob := filepath.Glob(filepath.Join(dir, segmentPrefix+"*"))
+		req.NoError(errGlob)
+		return len(files)
+	}
+	tester.Equal(3, countSegments())
+	req.NoError(l.Done(seqs[1]))
+	tester.Equal(3, countSegments(), "the segments after a pending one are kept")
+	req.NoError(l.Done(seqs[0]))
+	tester.Equal(1, countSegments())
+	req.NoError(l.Done(seqs[2]))
+	tester.Equal(1, countSegments(), "the active segment is kept")
+}